@@ -0,0 +1,228 @@
+package dygo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// tagName is the struct tag used to configure how dygo stores a field, e.g. `dygo:"encrypt"`.
+const tagName = "dygo"
+
+// envelopeMagic prefixes every binary attribute value written by dygo in a transformed form.
+// The byte following the magic identifies the kind of the envelope.
+const envelopeMagic = "\x00dygo"
+
+const (
+	envelopeEncrypted  byte = 'e'
+	envelopeCompressed byte = 'z'
+	envelopePointer    byte = 'p'
+)
+
+// fieldOptions holds the dygo struct tag options of a single field.
+type fieldOptions struct {
+	attributeName string
+	encrypt       bool
+	compress      bool
+	offload       bool
+}
+
+// fieldOptionsCache caches the parsed dygo struct tags per struct type.
+var fieldOptionsCache sync.Map
+
+// taggedFields returns the options of all fields of v carrying a dygo struct tag.
+func taggedFields(v any) []fieldOptions {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	if cached, ok := fieldOptionsCache.Load(t); ok {
+		return cached.([]fieldOptions)
+	}
+	fields := collectTaggedFields(t)
+	fieldOptionsCache.Store(t, fields)
+	return fields
+}
+
+// collectTaggedFields walks the fields of the struct type t, including embedded structs.
+func collectTaggedFields(t reflect.Type) []fieldOptions {
+	var fields []fieldOptions
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		avTag := strings.Split(f.Tag.Get("dynamodbav"), ",")[0]
+		if avTag == "-" {
+			continue
+		}
+		if f.Anonymous && avTag == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				fields = append(fields, collectTaggedFields(ft)...)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		tag, ok := f.Tag.Lookup(tagName)
+		if !ok {
+			continue
+		}
+		opts := fieldOptions{attributeName: avTag}
+		if opts.attributeName == "" {
+			opts.attributeName = f.Name
+		}
+		for _, option := range strings.Split(tag, ",") {
+			switch strings.TrimSpace(option) {
+			case "encrypt":
+				opts.encrypt = true
+			case "compress":
+				opts.compress = true
+			case "offload":
+				opts.offload = true
+			}
+		}
+		fields = append(fields, opts)
+	}
+	return fields
+}
+
+// marshalItem marshals the item into a map of attribute values and applies the dygo struct tag options of its fields.
+func (c *Client) marshalItem(ctx context.Context, item ItemData) (map[string]types.AttributeValue, error) {
+	av, err := attributevalue.MarshalMap(item)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.transformItem(ctx, av, taggedFields(item)); err != nil {
+		return nil, err
+	}
+	return av, nil
+}
+
+// transformItem applies the given field options to the attributes of a marshalled item, in place:
+// it encodes them, then offloads them to the blob store if the item is too large.
+func (c *Client) transformItem(ctx context.Context, av map[string]types.AttributeValue, fields []fieldOptions) error {
+	if err := c.encodeAttributes(ctx, av, fields); err != nil {
+		return err
+	}
+	return c.offloadAttributes(ctx, av, fields)
+}
+
+// encodeAttributes stores the blind indexes registered with WithBlindIndex
+// and transforms the attributes of av according to the given field options.
+func (c *Client) encodeAttributes(ctx context.Context, av map[string]types.AttributeValue, fields []fieldOptions) error {
+	for attributeName, indexName := range c.blindIndexes {
+		v, ok := av[attributeName]
+		if !ok {
+			continue
+		}
+		index, err := blindIndexValue(c.blindIndexKey, attributeName, v)
+		if err != nil {
+			return fmt.Errorf("attribute %q: %w", attributeName, err)
+		}
+		av[indexName] = &types.AttributeValueMemberS{Value: index}
+	}
+	for _, f := range fields {
+		v, ok := av[f.attributeName]
+		if !ok {
+			continue
+		}
+		if !f.encrypt && !f.compress {
+			continue
+		}
+		data, err := marshalDynamoDBJSON(v)
+		if err != nil {
+			return fmt.Errorf("attribute %q: %w", f.attributeName, err)
+		}
+		compress := f.compress && len(data) >= c.compressionMinSize()
+		if !compress && !f.encrypt {
+			continue
+		}
+		if compress {
+			data, err = c.compress(data)
+			if err != nil {
+				return fmt.Errorf("attribute %q: %w", f.attributeName, err)
+			}
+		}
+		if f.encrypt {
+			if c.keyProvider == nil {
+				return fmt.Errorf("attribute %q is tagged for encryption but no key provider is configured", f.attributeName)
+			}
+			data, err = encrypt(ctx, c.keyProvider, f.attributeName, data)
+			if err != nil {
+				return fmt.Errorf("attribute %q: %w", f.attributeName, err)
+			}
+		}
+		av[f.attributeName] = &types.AttributeValueMemberB{Value: data}
+	}
+	return nil
+}
+
+// decodeItems decodes every item in place, see decodeItem.
+func (c *Client) decodeItems(ctx context.Context, items []map[string]types.AttributeValue) error {
+	for _, item := range items {
+		if err := c.decodeItem(ctx, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeItem reverses the transformations applied by marshalItem on the attributes of a raw item, in place.
+// Transformed attributes are self-describing, so no knowledge of the target type is required.
+func (c *Client) decodeItem(ctx context.Context, item map[string]types.AttributeValue) error {
+	for name, v := range item {
+		b, ok := v.(*types.AttributeValueMemberB)
+		if !ok || !isEnvelope(b.Value) {
+			continue
+		}
+		av, err := c.decodeAttribute(ctx, name, b.Value)
+		if err != nil {
+			return err
+		}
+		item[name] = av
+	}
+	return nil
+}
+
+// decodeAttribute unwraps the envelopes of a transformed attribute until the original attribute value is reached.
+func (c *Client) decodeAttribute(ctx context.Context, name string, data []byte) (types.AttributeValue, error) {
+	for isEnvelope(data) {
+		kind, payload := data[len(envelopeMagic)], data[len(envelopeMagic)+1:]
+		var err error
+		switch kind {
+		case envelopeEncrypted:
+			data, err = decrypt(ctx, c.keyProvider, name, payload)
+		case envelopeCompressed:
+			data, err = c.decompress(payload)
+		case envelopePointer:
+			data, err = c.fetchBlob(ctx, payload)
+		default:
+			err = fmt.Errorf("unknown envelope kind %q", kind)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("attribute %q: %w", name, err)
+		}
+	}
+	av, err := unmarshalDynamoDBJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("attribute %q: %w", name, err)
+	}
+	return av, nil
+}
+
+// isEnvelope reports whether data is a dygo envelope.
+func isEnvelope(data []byte) bool {
+	return len(data) > len(envelopeMagic) && bytes.HasPrefix(data, []byte(envelopeMagic))
+}
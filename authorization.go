@@ -10,9 +10,11 @@ import (
 type output struct {
 	Results          []map[string]types.AttributeValue
 	LastEvaluatedKey map[string]types.AttributeValue
-	item             *Item
-	ctx              context.Context
-	bypassAuth       bool
+	// NextToken is set by Execute when Limit stopped the read of a PartiQL statement before its last page.
+	NextToken  string
+	item       *Item
+	ctx        context.Context
+	bypassAuth bool
 }
 
 func newOutput(item *Item, ctx context.Context) *output {
@@ -83,7 +85,7 @@ func (o *output) Unmarshal(out Out, entityTypes []string) *output {
 	if !o.bypassAuth {
 		err := out.Authorize(o.ctx)
 		if err != nil {
-			o.item.err = dynamoError().method("authorization").message(err.Error())
+			o.item.err = dynamoError().method("authorization").kind(ErrUnauthorized).wrap(err)
 		}
 	}
 	return o
@@ -1,22 +1,13 @@
 package dygo
 
-import (
-	"context"
-	"time"
+import "context"
 
-	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
-	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
-	"golang.org/x/sync/errgroup"
-)
-
-const (
-	maxRetriesDelete = 3
-	opBatchDelete    = "BatchDelete"
-)
+const opBatchDelete = "BatchDelete"
 
 // BatchDeleteItem deletes multiple items in batches.
 // It takes a context and the number of threads to use for parallel processing.
-// It returns an error if any of the batch operations fail.
+// It returns an error if any of the batch operations fail, wrapping an UnprocessedItemsError with the keys
+// of the items DynamoDB didn't process after all the retries.
 //
 // Example :
 //
@@ -30,52 +21,36 @@ const (
 //		log.Fatal(err)
 //	}
 func (i *Item) BatchDeleteItem(ctx context.Context, threadCount int) error {
+	_, err := i.BatchDeleteItemResult(ctx, threadCount)
+	return err
+}
+
+// BatchDeleteItemResult deletes multiple items in batches like BatchDeleteItem,
+// and also returns the outcome of the deletion of each item.
+// With ContinueOnError, the failure of a batch doesn't stop the other batches.
+//
+// Example :
+//
+//	result, err := item.ContinueOnError().BatchDeleteItemResult(context.Background(), 10)
+//	for _, failure := range result.Failed {
+//		log.Printf("deletion of %v failed: %v", failure.Key, failure.Err)
+//	}
+func (i *Item) BatchDeleteItemResult(ctx context.Context, threadCount int) (*BatchResult, error) {
+	result := newBatchResult(opBatchDelete)
 	if i.err != nil {
-		return i.err
+		return result, i.err
 	}
-	g, ctx := errgroup.WithContext(ctx)
+	g, ctx := i.batchGroup(ctx)
 	g.SetLimit(threadCount)
 
 	for _, batch := range i.batchData.batchDelete {
 		batch := batch
 		g.Go(func() error {
-			return i.processBatchDelete(ctx, batch)
+			return processBatchWrite(ctx, opBatchDelete, batchTables{def: i.c}, batch, result)
 		})
 	}
 
-	// Wait for all batch operations to complete
-	if err := g.Wait(); err != nil {
-		return dynamoError().method(opBatchDelete).message(err.Error())
-	}
-
-	return nil
-}
-
-// processBatchDelete processes a batch of write requests for deleting items in DynamoDB.
-// It takes a context and a map of write requests as input and returns an error if any.
-// The function retries the unprocessed items if there are any, up to a maximum number of retries.
-func (i *Item) processBatchDelete(ctx context.Context, batch map[string][]types.WriteRequest) error {
-	var retries int
-	for {
-		input := &dynamodb.BatchWriteItemInput{
-			RequestItems: batch,
-		}
-
-		result, err := i.c.client.BatchWriteItem(ctx, input)
-		if err != nil {
-			return dynamoError().method(opBatchDelete).message(err.Error())
-		}
-
-		if len(result.UnprocessedItems) == 0 || retries >= maxRetriesDelete {
-			break
-		}
-
-		// Retry unprocessed items
-		batch = result.UnprocessedItems
-		retries++
-	}
-
-	// Simulate a delay to avoid throttling
-	time.Sleep(time.Second * 1)
-	return nil
+	// Wait for all batch operations to complete, their errors are recorded in the result
+	_ = g.Wait()
+	return result, result.Err()
 }
@@ -24,6 +24,31 @@ const opBatchGet = "BatchGet"
 //
 //	output, err := item.BatchGetItem(context.Background(), 10)
 func (i *Item) BatchGetItem(ctx context.Context, threadCount int) ([]map[string]types.AttributeValue, error) {
+	return i.batchGetItem(ctx, threadCount, nil)
+}
+
+// BatchGetItemOK retrieves multiple items like BatchGetItem, and also reports the keys for which there is no item.
+// The projection of the requested items, if any, must include the key attributes.
+//
+// Example:
+//
+//	item := new(Item)
+//	for _, gId := range gIds {
+//		db.PK(gId).SK(Equal(SK)).AddBatchGetItem(item, true)
+//	}
+//
+//	output, missing, err := item.BatchGetItemOK(context.Background(), 10)
+func (i *Item) BatchGetItemOK(ctx context.Context, threadCount int) ([]map[string]types.AttributeValue, []map[string]types.AttributeValue, error) {
+	missing := make([]map[string]types.AttributeValue, 0)
+	output, err := i.batchGetItem(ctx, threadCount, &missing)
+	if err != nil {
+		return nil, nil, err
+	}
+	return output, missing, nil
+}
+
+// batchGetItem runs the batches of the Item, appending the keys for which there is no item to missing, if not nil.
+func (i *Item) batchGetItem(ctx context.Context, threadCount int, missing *[]map[string]types.AttributeValue) ([]map[string]types.AttributeValue, error) {
 	if i.err != nil {
 		return nil, i.err
 	}
@@ -36,12 +61,12 @@ func (i *Item) BatchGetItem(ctx context.Context, threadCount int) ([]map[string]
 	for _, batch := range i.batchData.batchGet {
 		batch := batch
 		g.Go(func() error {
-			return i.fetchBatch(ctx, batch, &output, &mu)
+			return i.fetchBatch(ctx, batch, &output, missing, &mu)
 		})
 	}
 
 	if err := g.Wait(); err != nil {
-		return nil, dynamoError().method(opBatchGet).message(err.Error())
+		return nil, err
 	}
 
 	return output, nil
@@ -82,29 +107,66 @@ func (i *Item) BatchGetAuthorizedItem(ctx context.Context, threadCount int) *out
 	for _, batch := range i.batchData.batchGet {
 		batch := batch
 		g.Go(func() error {
-			return i.fetchBatch(ctx, batch, &output, &mu)
+			return i.fetchBatch(ctx, batch, &output, nil, &mu)
 		})
 	}
 
 	if err := g.Wait(); err != nil {
-		result.item.err = dynamoError().method(opBatchGet).message(err.Error())
+		result.item.err = err
 	}
 	result.Results = append(result.Results, output...)
 	return result
 }
 
 // fetchBatch fetches items in batches from DynamoDB using BatchGetItem API.
-func (i *Item) fetchBatch(ctx context.Context, batch map[string]types.KeysAndAttributes, output *[]map[string]types.AttributeValue, mu *sync.Mutex) error {
+// The keys for which there is no item are appended to missing, if not nil.
+func (i *Item) fetchBatch(ctx context.Context, batch map[string]types.KeysAndAttributes, output, missing *[]map[string]types.AttributeValue, mu *sync.Mutex) error {
+	useCache := i.c.cache != nil && !i.bypassCache
+	requested := batch
+	if useCache {
+		var hits []map[string]types.AttributeValue
+		batch, hits = i.c.cachedBatch(batch)
+		mu.Lock()
+		*output = append(*output, hits...)
+		mu.Unlock()
+		if len(batch) == 0 {
+			return nil
+		}
+	}
+	var generation uint64
+	if useCache {
+		generation = i.c.beginCacheFill()
+		defer i.c.endCacheFill()
+	}
+
 	input := &dynamodb.BatchGetItemInput{
 		RequestItems: batch,
 	}
 
 	paginator := newBatchGetItemPaginator(i.c.client, input)
+	found := make(map[string]bool)
 
 	for paginator.hasMorePages() {
 		page, err := paginator.nextPage(ctx)
 		if err != nil {
-			return dynamoError().method(opBatchGet).message(err.Error())
+			return i.opError(opBatchGet, err)
+		}
+		for table, items := range page.Responses {
+			if err := i.c.decodeItems(ctx, items); err != nil {
+				return dynamoError().method(opBatchGet).wrap(err)
+			}
+			if len(requested[table].Keys) == 0 {
+				continue
+			}
+			for _, item := range items {
+				key := keyOf(item, requested[table].Keys[0])
+				if useCache && requested[table].ProjectionExpression == nil {
+					i.c.cacheItem(generation, table, key, item)
+				}
+				if missing != nil {
+					found[cacheKey(table, key)] = true
+				}
+			}
 		}
 
 		mu.Lock()
@@ -113,5 +175,17 @@ func (i *Item) fetchBatch(ctx context.Context, batch map[string]types.KeysAndAtt
 		}
 		mu.Unlock()
 	}
+
+	if missing != nil {
+		mu.Lock()
+		defer mu.Unlock()
+		for table, keysAndAttributes := range batch {
+			for _, key := range keysAndAttributes.Keys {
+				if !found[cacheKey(table, key)] {
+					*missing = append(*missing, key)
+				}
+			}
+		}
+	}
 	return nil
 }
@@ -4,6 +4,9 @@ import (
 	"context"
 	"log"
 	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
 )
 
 func Test_batchgetauthorized_item_happy_path(t *testing.T) {
@@ -383,3 +386,24 @@ func Test_batchgetauthorized_item_bypassAuth_happy_path(t *testing.T) {
 	// remove item
 	removeItems(t, gIds, SK)
 }
+
+func Test_batchget_item_missing_keys(t *testing.T) {
+	fake := newFakeDynamoDB()
+	db := newFakeClient(t, fake)
+	pk := newPK("room")
+	fake.put("test-table-1", map[string]types.AttributeValue{
+		"_partition_key": &types.AttributeValueMemberS{Value: pk},
+		"_sort_key":      &types.AttributeValueMemberS{Value: "current"},
+	})
+
+	item := new(Item)
+	db.PK(pk).SK(Equal("current")).AddBatchGetItem(item, true)
+	db.PK(pk).SK(Equal("previous")).AddBatchGetItem(item, true)
+	output, missing, err := item.BatchGetItemOK(context.Background(), 2)
+	assert.NoError(t, err)
+	assert.Len(t, output, 1)
+	assert.Equal(t, []map[string]types.AttributeValue{{
+		"_partition_key": &types.AttributeValueMemberS{Value: pk},
+		"_sort_key":      &types.AttributeValueMemberS{Value: "previous"},
+	}}, missing)
+}
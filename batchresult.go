@@ -0,0 +1,180 @@
+package dygo
+
+import (
+	"context"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"golang.org/x/sync/errgroup"
+)
+
+// BatchKey is the primary key of an item of a batch operation, with its table.
+type BatchKey struct {
+	Table string
+	Key   map[string]types.AttributeValue
+}
+
+// BatchFailure is an item of a batch operation whose write failed.
+type BatchFailure struct {
+	BatchKey
+	Err error
+}
+
+// BatchResult is the outcome, item by item, of a batch operation such as BatchUpsertItemResult.
+// Unless ContinueOnError is set, the operation stops at the first failure. The items it didn't attempt,
+// because it stopped or its context was canceled, are reported as Skipped.
+type BatchResult struct {
+	// Succeeded are the keys of the items written.
+	Succeeded []BatchKey
+	// Failed are the keys of the items whose write failed, with the error.
+	Failed []BatchFailure
+	// Unprocessed are the keys of the items DynamoDB left unprocessed after all the retries.
+	Unprocessed []BatchKey
+	// Skipped are the keys of the items not attempted because the operation stopped.
+	Skipped []BatchKey
+	// ConsumedCapacity is the capacity consumed by the requests of the operation.
+	ConsumedCapacity []types.ConsumedCapacity
+
+	op  string
+	mu  sync.Mutex
+	err error
+}
+
+// ContinueOnError makes the batch operations, such as BatchUpsertItemResult, BatchDeleteItemResult
+// and UpdateResult, go on after the failure of an item or a batch instead of stopping at the first failure.
+// The failures are reported by the BatchResult of the operation.
+//
+// Example:
+//
+//	result, err := item.ContinueOnError().BatchUpsertItemResult(context.Background(), 10)
+func (i *Item) ContinueOnError() *Item {
+	i.continueOnError = true
+	return i
+}
+
+// batchGroup returns the group running the batches of a batch operation.
+// Unless ContinueOnError is set, the context of the group is canceled by the first failure.
+// The errors of the batches are recorded in the BatchResult of the operation, so the error of the group can be ignored.
+func (i *Item) batchGroup(ctx context.Context) (*errgroup.Group, context.Context) {
+	return newBatchGroup(ctx, i.continueOnError)
+}
+
+// newBatchGroup returns the group running the batches of a batch operation, see batchGroup.
+func newBatchGroup(ctx context.Context, continueOnError bool) (*errgroup.Group, context.Context) {
+	if continueOnError {
+		return new(errgroup.Group), ctx
+	}
+	return errgroup.WithContext(ctx)
+}
+
+// newBatchResult returns an empty BatchResult of the operation.
+func newBatchResult(op string) *BatchResult {
+	return &BatchResult{op: op}
+}
+
+// Err returns nil if all the items were written. Otherwise it returns the first failure of the operation,
+// or an error matching ErrThrottled and wrapping an UnprocessedItemsError if items were left unprocessed.
+func (r *BatchResult) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	if len(r.Unprocessed) == 0 {
+		return nil
+	}
+	unprocessed := &UnprocessedItemsError{Keys: make(map[string][]map[string]types.AttributeValue)}
+	for _, k := range r.Unprocessed {
+		unprocessed.Keys[k.Table] = append(unprocessed.Keys[k.Table], k.Key)
+	}
+	return dynamoError().method(r.op).kind(ErrThrottled).wrap(unprocessed)
+}
+
+// addError records an error of the operation that isn't the failure of an item.
+func (r *BatchResult) addError(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+// succeed records the item of the key as written.
+func (r *BatchResult) succeed(key BatchKey, consumed ...types.ConsumedCapacity) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Succeeded = append(r.Succeeded, key)
+	r.ConsumedCapacity = append(r.ConsumedCapacity, consumed...)
+}
+
+// fail records the write of the items of the keys as failed with err.
+func (r *BatchResult) fail(err error, keys ...BatchKey) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, key := range keys {
+		r.Failed = append(r.Failed, BatchFailure{BatchKey: key, Err: err})
+	}
+	if r.err == nil && len(keys) > 0 {
+		r.err = err
+	}
+}
+
+// unprocessed records the items of the keys as left unprocessed after all the retries.
+func (r *BatchResult) unprocessed(keys ...BatchKey) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Unprocessed = append(r.Unprocessed, keys...)
+}
+
+// skip records the items of the keys as not attempted.
+func (r *BatchResult) skip(keys ...BatchKey) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Skipped = append(r.Skipped, keys...)
+}
+
+// recordWrites records the outcome of the write requests of a batch, as returned by writeBatch:
+// the pending requests failed with err, or were left unprocessed if err is nil, and the others were written.
+func (r *BatchResult) recordWrites(tables batchTables, requests, pending map[string][]types.WriteRequest, consumed []types.ConsumedCapacity, err error) {
+	left := make(map[string]bool)
+	var pendingKeys []BatchKey
+	for table, writes := range pending {
+		for _, w := range writes {
+			key := writeRequestKey(tables.client(table), w)
+			left[cacheKey(table, key)] = true
+			pendingKeys = append(pendingKeys, BatchKey{Table: table, Key: key})
+		}
+	}
+
+	var written []BatchKey
+	for table, writes := range requests {
+		for _, w := range writes {
+			key := writeRequestKey(tables.client(table), w)
+			if !left[cacheKey(table, key)] {
+				written = append(written, BatchKey{Table: table, Key: key})
+			}
+		}
+	}
+
+	if err != nil {
+		r.fail(err, pendingKeys...)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Succeeded = append(r.Succeeded, written...)
+	if err == nil {
+		r.Unprocessed = append(r.Unprocessed, pendingKeys...)
+	}
+	r.ConsumedCapacity = append(r.ConsumedCapacity, consumed...)
+}
+
+// batchKeys returns the keys of the items of the write requests.
+func batchKeys(tables batchTables, requests map[string][]types.WriteRequest) []BatchKey {
+	var keys []BatchKey
+	for table, writes := range requests {
+		for _, w := range writes {
+			keys = append(keys, BatchKey{Table: table, Key: writeRequestKey(tables.client(table), w)})
+		}
+	}
+	return keys
+}
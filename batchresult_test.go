@@ -0,0 +1,124 @@
+package dygo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_batch_result(t *testing.T) {
+	fastBackoff(t)
+	fake := newFakeDynamoDB()
+	db := newFakeClient(t, fake)
+	failing, unprocessed := newPK("room"), newPK("room")
+	fake.hook = func(op string, req map[string]json.RawMessage) (int, any) {
+		var requests map[string][]struct {
+			PutRequest *struct{ Item json.RawMessage }
+		}
+		json.Unmarshal(req["RequestItems"], &requests)
+		for _, writes := range requests {
+			for _, w := range writes {
+				switch {
+				case strings.Contains(string(w.PutRequest.Item), failing):
+					return http.StatusBadRequest, fakeError("ValidationException", "invalid item")
+				case strings.Contains(string(w.PutRequest.Item), unprocessed):
+					return http.StatusOK, map[string]any{
+						"UnprocessedItems": map[string]any{"test-table-1": []any{w}},
+						"ConsumedCapacity": []any{map[string]any{"TableName": "test-table-1", "CapacityUnits": 1}},
+					}
+				}
+			}
+		}
+		return 0, nil
+	}
+
+	item := new(Item)
+	for n := 0; n < writeBatchSize; n++ {
+		db.Item(dataItem{PK: newPK("room"), SK: "current", EntityType: "room"}).AddBatchUpsertItem(item)
+	}
+	db.Item(dataItem{PK: failing, SK: "current", EntityType: "room"}).AddBatchUpsertItem(item)
+	for n := 0; n < writeBatchSize-1; n++ {
+		db.Item(dataItem{PK: newPK("room"), SK: "current", EntityType: "room"}).AddBatchUpsertItem(item)
+	}
+	db.Item(dataItem{PK: unprocessed, SK: "current", EntityType: "room"}).AddBatchUpsertItem(item)
+
+	result, err := item.ContinueOnError().BatchUpsertItemResult(context.Background(), 1)
+	assert.True(t, errors.Is(err, ErrValidation), err)
+	assert.Len(t, result.Failed, writeBatchSize)
+	assert.True(t, errors.Is(result.Failed[0].Err, ErrValidation))
+	assert.Len(t, result.Succeeded, writeBatchSize)
+	assert.Equal(t, []BatchKey{{Table: "test-table-1", Key: map[string]types.AttributeValue{
+		"_partition_key": &types.AttributeValueMemberS{Value: unprocessed},
+		"_sort_key":      &types.AttributeValueMemberS{Value: "current"},
+	}}}, result.Unprocessed)
+	assert.Len(t, result.ConsumedCapacity, maxBatchWriteRetries+1)
+
+	// without ContinueOnError the first failure cancels the following batches
+	item.continueOnError = false
+	result, err = item.BatchUpsertItemResult(context.Background(), 1)
+	assert.True(t, errors.Is(err, ErrValidation), err)
+	assert.Len(t, result.Succeeded, writeBatchSize)
+	assert.Len(t, result.Failed, writeBatchSize)
+	assert.Len(t, result.Skipped, 1)
+	assert.Empty(t, result.Unprocessed)
+
+	// the batches aren't sent with a canceled context
+	calls := fake.callCount("BatchWriteItem")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result, err = item.BatchUpsertItemResult(ctx, 1)
+	assert.True(t, errors.Is(err, context.Canceled), err)
+	assert.Len(t, result.Skipped, 2*writeBatchSize+1)
+	assert.Empty(t, result.Failed)
+	assert.Equal(t, calls, fake.callCount("BatchWriteItem"))
+}
+
+func Test_update_result(t *testing.T) {
+	fake := newFakeDynamoDB()
+	db := newFakeClient(t, fake)
+	failing := newPK("room")
+	fake.hook = func(op string, req map[string]json.RawMessage) (int, any) {
+		if op == "UpdateItem" && strings.Contains(string(req["Key"]), failing) {
+			return http.StatusBadRequest, fakeError("ValidationException", "invalid update")
+		}
+		return 0, nil
+	}
+
+	item := new(Item)
+	for _, pk := range []string{newPK("room"), failing, newPK("room")} {
+		db.UpdateItemRaw(map[string]types.AttributeValue{
+			"_partition_key": &types.AttributeValueMemberS{Value: pk},
+			"_sort_key":      &types.AttributeValueMemberS{Value: "current"},
+			"logical_name":   &types.AttributeValueMemberS{Value: "hall"},
+		}).AddUpdateRawItem(item)
+	}
+
+	result, err := item.ContinueOnError().UpdateResult(context.Background(), 1)
+	assert.True(t, errors.Is(err, ErrValidation), err)
+	assert.Len(t, result.Succeeded, 2)
+	if assert.Len(t, result.Failed, 1) {
+		assert.Equal(t, &types.AttributeValueMemberS{Value: failing}, result.Failed[0].Key["_partition_key"])
+	}
+
+	item.continueOnError = false
+	result, err = item.UpdateResult(context.Background(), 1)
+	assert.Error(t, err)
+	assert.Len(t, result.Succeeded, 1)
+	assert.Len(t, result.Failed, 1)
+	assert.Len(t, result.Skipped, 1)
+
+	// the items aren't updated with a canceled context
+	calls := fake.callCount("UpdateItem")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result, err = item.ContinueOnError().UpdateResult(ctx, 2)
+	assert.True(t, errors.Is(err, context.Canceled), err)
+	assert.Len(t, result.Skipped, 3)
+	assert.Equal(t, calls, fake.callCount("UpdateItem"))
+}
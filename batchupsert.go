@@ -2,20 +2,16 @@ package dygo
 
 import (
 	"context"
-	"time"
 
-	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
-	"golang.org/x/sync/errgroup"
 )
 
 const opBatchUpsert = "BatchUpsert"
 
-const maxRetriesUpsert = 3
-
 // BatchUpsertItem performs batch upsert operations on items.
 // It takes a context and the number of threads to use for parallel processing.
-// It returns an error if any of the batch operations fail.
+// It returns an error if any of the batch operations fail, wrapping an UnprocessedItemsError with the keys
+// of the items DynamoDB didn't process after all the retries.
 //
 // Example :
 //
@@ -35,50 +31,77 @@ const maxRetriesUpsert = 3
 //		log.Fatal(err)
 //	}
 func (i *Item) BatchUpsertItem(ctx context.Context, threadCount int) error {
+	_, err := i.BatchUpsertItemResult(ctx, threadCount)
+	return err
+}
+
+// BatchUpsertItemResult performs batch upsert operations on items like BatchUpsertItem,
+// and also returns the outcome of the upsert of each item.
+// With ContinueOnError, the failure of a batch doesn't stop the other batches.
+//
+// Example :
+//
+//	result, err := newItem.ContinueOnError().BatchUpsertItemResult(context.Background(), 10)
+//	for _, failure := range result.Failed {
+//		log.Printf("upsert of %v failed: %v", failure.Key, failure.Err)
+//	}
+func (i *Item) BatchUpsertItemResult(ctx context.Context, threadCount int) (*BatchResult, error) {
+	result := newBatchResult(opBatchUpsert)
 	if i.err != nil {
-		return i.err
+		return result, i.err
 	}
 
-	g, ctx := errgroup.WithContext(ctx)
+	batches, err := i.putBatches(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	g, ctx := i.batchGroup(ctx)
 	g.SetLimit(threadCount)
 
-	for _, batch := range i.batchData.batchPut {
+	for _, batch := range batches {
 		batch := batch
 		g.Go(func() error {
-			return i.processBatchUpsert(ctx, batch)
+			return processBatchWrite(ctx, opBatchUpsert, batchTables{def: i.c}, batch, result)
 		})
 	}
 
-	// Wait for all batch operations to complete
-	if err := g.Wait(); err != nil {
-		return dynamoError().method(opBatchUpsert).message(err.Error())
-	}
-	return nil
+	// Wait for all batch operations to complete, their errors are recorded in the result
+	_ = g.Wait()
+	return result, result.Err()
 }
 
-// processBatchUpsert processes a batch of write requests and performs batch upsert operation in DynamoDB.
-func (i *Item) processBatchUpsert(ctx context.Context, batch map[string][]types.WriteRequest) error {
-	var retries int
-	for {
-		input := &dynamodb.BatchWriteItemInput{
-			RequestItems: batch,
+// putBatches transforms the items added to the batch upsert and packs them in batches
+// of up to writeBatchSize requests and maxBatchWriteSize bytes.
+// If an item can't be transformed or is too large, the blobs already offloaded for the items are deleted.
+func (i *Item) putBatches(ctx context.Context) ([]map[string][]types.WriteRequest, error) {
+	var batches []map[string][]types.WriteRequest
+	var count, size int
+	var offloaded []string
+	for _, p := range i.batchData.batchPut {
+		av := p.item
+		if p.transform {
+			// the marshalled item is kept intact, so the batch can run again
+			av = copyItem(p.item)
+			err := i.c.transformItem(ctx, av, p.fields)
+			offloaded = append(offloaded, blobKeys(av)...)
+			if err == nil {
+				err = i.c.checkItemSize(av)
+			}
+			if err != nil {
+				return nil, dynamoError().method(opBatchUpsert).wrap(joinCleanupError(err, i.c.deleteBlobs(ctx, offloaded)))
+			}
 		}
 
-		result, err := i.c.client.BatchWriteItem(ctx, input)
-		if err != nil {
-			return dynamoError().method(opBatchUpsert).message(err.Error())
+		itemSize := ItemSize(av)
+		if len(batches) == 0 || count >= writeBatchSize || size+itemSize > maxBatchWriteSize {
+			batches = append(batches, make(map[string][]types.WriteRequest))
+			count, size = 0, 0
 		}
-
-		if len(result.UnprocessedItems) == 0 || retries >= maxRetriesUpsert {
-			break
-		}
-
-		// Retry unprocessed items
-		batch = result.UnprocessedItems
-		retries++
+		batch := batches[len(batches)-1]
+		batch[p.table] = append(batch[p.table], types.WriteRequest{PutRequest: &types.PutRequest{Item: av}})
+		count++
+		size += itemSize
 	}
-
-	// Sleep for 1 second to avoid throttling
-	time.Sleep(time.Second * 1)
-	return nil
+	return batches, nil
 }
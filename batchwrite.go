@@ -0,0 +1,286 @@
+package dygo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// maxBatchWriteRetries is the number of times the unprocessed requests of a batch write are retried.
+const maxBatchWriteRetries = 5
+
+// minWriteRate is the lowest rate, in writes per second, the rate controller backs off to.
+const minWriteRate = 1.0
+
+// The bounds of the exponential backoff between the retries of a batch write.
+var (
+	backoffBase = 50 * time.Millisecond
+	backoffMax  = 5 * time.Second
+)
+
+// UnprocessedItemsError is the error of a batch write that left requests unprocessed after all its retries.
+// It matches ErrThrottled with errors.Is.
+//
+// Example:
+//
+//	var unprocessed *dygo.UnprocessedItemsError
+//	if errors.As(err, &unprocessed) {
+//		for table, keys := range unprocessed.Keys {
+//			...
+//		}
+//	}
+type UnprocessedItemsError struct {
+	// Keys are the primary keys of the unprocessed items, by table.
+	Keys map[string][]map[string]types.AttributeValue
+}
+
+// Error returns the number of unprocessed items.
+func (e *UnprocessedItemsError) Error() string {
+	var n int
+	for _, keys := range e.Keys {
+		n += len(keys)
+	}
+	return fmt.Sprintf("%d items left unprocessed after all the retries", n)
+}
+
+// Is reports whether target is ErrThrottled, the cause of the unprocessed items.
+func (e *UnprocessedItemsError) Is(target error) bool {
+	return target == ErrThrottled
+}
+
+// writeRequestKey returns the primary key of the item of a write request.
+func writeRequestKey(c *Client, w types.WriteRequest) map[string]types.AttributeValue {
+	if w.DeleteRequest != nil {
+		return w.DeleteRequest.Key
+	}
+	if w.PutRequest != nil {
+		return c.primaryKey(w.PutRequest.Item)
+	}
+	return nil
+}
+
+// writeBatch sends the write requests with BatchWriteItem, at the rate set with WithWriteRate if any.
+// Throttled calls and unprocessed requests are retried with an exponential backoff and jitter.
+// It returns the requests still unprocessed after maxBatchWriteRetries retries, or, with the error of a failed call,
+// the requests of the call, along with the capacity consumed by the calls.
+func (c *Client) writeBatch(ctx context.Context, op string, batch map[string][]types.WriteRequest) (map[string][]types.WriteRequest, []types.ConsumedCapacity, error) {
+	var consumed []types.ConsumedCapacity
+	for attempt := 0; ; attempt++ {
+		reservation, err := c.writeRate.wait(ctx, countRequests(batch))
+		if err != nil {
+			return batch, consumed, dynamoError().method(op).wrap(err)
+		}
+
+		result, err := c.client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{
+			RequestItems:           batch,
+			ReturnConsumedCapacity: types.ReturnConsumedCapacityTotal,
+		})
+		if err != nil {
+			opErr := c.opError(op, nil, err)
+			if !errors.Is(opErr, ErrThrottled) {
+				return batch, consumed, opErr
+			}
+			// the whole batch was throttled and is retried as is
+		} else {
+			consumed = append(consumed, result.ConsumedCapacity...)
+			if len(result.UnprocessedItems) == 0 {
+				c.writeRate.succeeded()
+				return nil, consumed, nil
+			}
+			batch = result.UnprocessedItems
+		}
+
+		c.writeRate.throttled(reservation)
+		if attempt >= maxBatchWriteRetries {
+			return batch, consumed, nil
+		}
+		if err := sleepContext(ctx, backoff(attempt)); err != nil {
+			return batch, consumed, dynamoError().method(op).wrap(err)
+		}
+	}
+}
+
+// countRequests returns the number of write requests of a batch.
+func countRequests(batch map[string][]types.WriteRequest) int {
+	var n int
+	for _, writes := range batch {
+		n += len(writes)
+	}
+	return n
+}
+
+// backoff returns the delay before the retry following the attempt, counted from 0:
+// an exponential backoff with full jitter, bounded by backoffMax.
+func backoff(attempt int) time.Duration {
+	d := backoffMax
+	if attempt < 32 && backoffBase<<attempt < backoffMax {
+		d = backoffBase << attempt
+	}
+	return time.Duration(rand.Int63n(int64(d)) + 1)
+}
+
+// rateController is a token bucket limiting the writes of a client to a rate, in writes per second.
+// The rate is adjusted by additive increase and multiplicative decrease (AIMD):
+// it is halved when DynamoDB throttles writes, at most once per round of writes, and grows back towards the target as writes succeed.
+// The methods of a nil rateController do nothing.
+type rateController struct {
+	mu     sync.Mutex
+	target float64
+	rate   float64
+	tokens float64
+	last   time.Time
+	// issued counts the reservations of wait, decreased is the count when the rate was last halved
+	issued    uint64
+	decreased uint64
+}
+
+// newRateController returns a rateController starting at the target rate.
+func newRateController(target float64) *rateController {
+	return &rateController{target: target, rate: target, tokens: target, last: time.Now()}
+}
+
+// wait blocks until n writes can be sent, returning early with the context error if ctx is done.
+// The tokens are reserved before waiting, so concurrent writers are served in turn.
+// It returns the reservation to report to throttled if the writes are throttled.
+func (r *rateController) wait(ctx context.Context, n int) (uint64, error) {
+	if r == nil {
+		return 0, nil
+	}
+	r.mu.Lock()
+	now := time.Now()
+	r.tokens = math.Min(math.Max(r.rate, 1), r.tokens+now.Sub(r.last).Seconds()*r.rate)
+	r.last = now
+	r.tokens -= float64(n)
+	var d time.Duration
+	if r.tokens < 0 {
+		d = time.Duration(-r.tokens / r.rate * float64(time.Second))
+	}
+	r.issued++
+	reservation := r.issued
+	r.mu.Unlock()
+
+	if d == 0 {
+		return reservation, nil
+	}
+	return reservation, sleepContext(ctx, d)
+}
+
+// throttled halves the rate, down to minWriteRate, when the writes of the reservation were throttled.
+// Writes reserved before the last decrease were sent at the former rate, so their throttling doesn't halve the rate again:
+// concurrent writers throttled by the same burst decrease the rate once.
+func (r *rateController) throttled(reservation uint64) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if reservation <= r.decreased {
+		return
+	}
+	r.rate = math.Max(r.rate/2, math.Min(minWriteRate, r.target))
+	r.decreased = r.issued
+}
+
+// succeeded increases the rate by a tenth of the target, up to the target.
+func (r *rateController) succeeded() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rate = math.Min(r.rate+r.target/10, r.target)
+}
+
+// batchTables gives the client of each table of a batch write, which knows the key schema,
+// the blob store and the cache of the table. The tables without a client of their own use the default client,
+// whose DynamoDB client and write rate send the batch.
+type batchTables struct {
+	def     *Client
+	clients map[string]*Client
+}
+
+// client returns the client of the table.
+func (t batchTables) client(table string) *Client {
+	if c, ok := t.clients[table]; ok {
+		return c
+	}
+	return t.def
+}
+
+// processBatchWrite writes a batch of put and delete requests and cleans up the blobs of the overwritten and deleted items.
+// The outcome of the requests is recorded in the result. If ctx is already done, because the operation stopped
+// at a failure or was canceled, the requests aren't sent and are recorded as skipped.
+func processBatchWrite(ctx context.Context, op string, tables batchTables, batch map[string][]types.WriteRequest, result *BatchResult) error {
+	if err := ctx.Err(); err != nil {
+		err = dynamoError().method(op).wrap(err)
+		result.skip(batchKeys(tables, batch)...)
+		result.addError(err)
+		return joinCleanupError(err, deleteOrphanBlobs(ctx, op, tables, batch, result))
+	}
+
+	blobs := make(map[string][]string)
+	for table, writes := range batch {
+		keys, err := tables.client(table).fetchBlobKeys(ctx, map[string][]types.WriteRequest{table: writes})
+		if err != nil {
+			err = dynamoError().method(op).wrap(err)
+			result.fail(err, batchKeys(tables, batch)...)
+			return joinCleanupError(err, deleteOrphanBlobs(ctx, op, tables, batch, result))
+		}
+		blobs[table] = keys
+	}
+
+	pending, consumed, err := tables.def.writeBatch(ctx, op, batch)
+	for table, writes := range batch {
+		tables.client(table).invalidateBatchCache(map[string][]types.WriteRequest{table: writes})
+	}
+	result.recordWrites(tables, batch, pending, consumed, err)
+	if len(pending) > 0 {
+		// the stored items of the failed and unprocessed requests still reference their blobs
+		return joinCleanupError(err, deleteOrphanBlobs(ctx, op, tables, pending, result))
+	}
+
+	// blobs of the overwritten and deleted items are no longer referenced, unless an item was written unchanged
+	for table, writes := range batch {
+		var written []map[string]types.AttributeValue
+		for _, r := range writes {
+			if r.PutRequest != nil {
+				written = append(written, r.PutRequest.Item)
+			}
+		}
+		if err := tables.client(table).deleteBlobs(ctx, blobs[table], written...); err != nil {
+			err = dynamoError().method(op).wrap(err)
+			result.addError(err)
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteOrphanBlobs deletes the blobs offloaded for the items of the put requests, which were not written.
+// The blobs are deleted with cleanupContext, since the requests may not have been written because ctx is done.
+// The error of the cleanup is recorded in the result.
+func deleteOrphanBlobs(ctx context.Context, op string, tables batchTables, requests map[string][]types.WriteRequest, result *BatchResult) error {
+	ctx, cancel := cleanupContext(ctx)
+	defer cancel()
+	for table, writes := range requests {
+		var orphans []string
+		for _, r := range writes {
+			if r.PutRequest != nil {
+				orphans = append(orphans, blobKeys(r.PutRequest.Item)...)
+			}
+		}
+		if err := tables.client(table).deleteBlobs(ctx, orphans); err != nil {
+			err = dynamoError().method(op).wrap(err)
+			result.addError(err)
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package dygo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// fastBackoff shortens the backoff between the retries of batch writes for the duration of the test.
+func fastBackoff(t *testing.T) {
+	base, max := backoffBase, backoffMax
+	backoffBase, backoffMax = time.Millisecond, 5*time.Millisecond
+	t.Cleanup(func() { backoffBase, backoffMax = base, max })
+}
+
+func Test_batch_write_retries_unprocessed(t *testing.T) {
+	fastBackoff(t)
+	fake := newFakeDynamoDB()
+	db := newFakeClient(t, fake)
+
+	var mu sync.Mutex
+	failures := 2
+	fake.hook = func(op string, req map[string]json.RawMessage) (int, any) {
+		mu.Lock()
+		defer mu.Unlock()
+		if op != "BatchWriteItem" || failures == 0 {
+			return 0, nil
+		}
+		failures--
+		if failures == 1 {
+			return http.StatusBadRequest, fakeError("ProvisionedThroughputExceededException", "slow down")
+		}
+		return http.StatusOK, map[string]any{"UnprocessedItems": req["RequestItems"]}
+	}
+
+	pk := newPK("room")
+	item := new(Item)
+	db.Item(dataItem{PK: pk, SK: "current", EntityType: "room"}).AddBatchUpsertItem(item)
+	assert.NoError(t, item.BatchUpsertItem(context.Background(), 1))
+	assert.Equal(t, 3, fake.callCount("BatchWriteItem"))
+	assert.NotNil(t, fake.get("test-table-1", map[string]types.AttributeValue{
+		"_partition_key": &types.AttributeValueMemberS{Value: pk},
+		"_sort_key":      &types.AttributeValueMemberS{Value: "current"},
+	}))
+}
+
+func Test_batch_write_reports_unprocessed(t *testing.T) {
+	fastBackoff(t)
+	fake := newFakeDynamoDB()
+	db := newFakeClient(t, fake)
+	fake.hook = func(op string, req map[string]json.RawMessage) (int, any) {
+		return http.StatusOK, map[string]any{"UnprocessedItems": req["RequestItems"]}
+	}
+
+	pk := newPK("room")
+	item := new(Item)
+	db.PK(pk).SK(Equal("current")).AddBatchDeleteItem(item)
+	err := item.BatchDeleteItem(context.Background(), 1)
+	assert.True(t, errors.Is(err, ErrThrottled), err)
+	var unprocessed *UnprocessedItemsError
+	if assert.True(t, errors.As(err, &unprocessed)) {
+		assert.Equal(t, []map[string]types.AttributeValue{{
+			"_partition_key": &types.AttributeValueMemberS{Value: pk},
+			"_sort_key":      &types.AttributeValueMemberS{Value: "current"},
+		}}, unprocessed.Keys["test-table-1"])
+	}
+	assert.Equal(t, maxBatchWriteRetries+1, fake.callCount("BatchWriteItem"))
+}
+
+func Test_write_rate(t *testing.T) {
+	assert.Error(t, WithWriteRate(0)(&Client{}))
+
+	r := newRateController(100)
+	start := time.Now()
+	var reservations []uint64
+	for n := 0; n < 4; n++ {
+		reservation, err := r.wait(context.Background(), 50)
+		assert.NoError(t, err)
+		reservations = append(reservations, reservation)
+	}
+	// 200 writes at 100 per second, the first 100 from the initial tokens
+	assert.InDelta(t, float64(time.Second), float64(time.Since(start)), float64(200*time.Millisecond))
+
+	// the writes reserved before the decrease don't halve the rate again
+	for _, reservation := range reservations {
+		r.throttled(reservation)
+	}
+	assert.Equal(t, 50.0, r.rate)
+	reservation, _ := r.wait(context.Background(), 0)
+	r.throttled(reservation)
+	assert.Equal(t, 25.0, r.rate)
+	r.succeeded()
+	assert.Equal(t, 35.0, r.rate)
+	for n := 0; n < 20; n++ {
+		reservation, _ := r.wait(context.Background(), 0)
+		r.throttled(reservation)
+	}
+	assert.Equal(t, minWriteRate, r.rate)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := r.wait(ctx, 1000)
+	assert.Error(t, err)
+
+	var none *rateController
+	_, err = none.wait(context.Background(), 1000)
+	assert.NoError(t, err)
+	none.throttled(0)
+	none.succeeded()
+}
@@ -0,0 +1,232 @@
+package dygo
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"golang.org/x/sync/errgroup"
+)
+
+const opBatchWrite = "BatchWrite"
+
+const (
+	defaultWriterMaxLatency  = 100 * time.Millisecond
+	defaultWriterConcurrency = 10
+)
+
+// BatchWriter collects put and delete requests produced over time and writes them in the background
+// with BatchWriteItem, once 25 requests are pending or after a maximum latency.
+// A request replaces the pending request with the same key, since DynamoDB rejects duplicate keys in one batch.
+// Requests for the same key in different batches may be written in any order when batches run concurrently.
+// It is safe for concurrent use.
+type BatchWriter struct {
+	c           *Client
+	ctx         context.Context
+	maxLatency  time.Duration
+	concurrency int
+
+	mu      sync.Mutex
+	pending []batchWrite
+	keys    map[string]int
+	timer   *time.Timer
+	closed  bool
+	g       errgroup.Group
+	result  *BatchResult
+}
+
+// BatchWriterOption configures a BatchWriter.
+type BatchWriterOption func(*BatchWriter)
+
+// batchWrite is a write request pending in a BatchWriter, with its table.
+type batchWrite struct {
+	table   string
+	request types.WriteRequest
+}
+
+// WithWriterMaxLatency sets how long a request can wait for its batch to fill up before it is written. It defaults to 100ms.
+func WithWriterMaxLatency(d time.Duration) BatchWriterOption {
+	return func(w *BatchWriter) {
+		if d > 0 {
+			w.maxLatency = d
+		}
+	}
+}
+
+// WithWriterConcurrency sets the number of batches written in parallel. It defaults to 10.
+// Put and Delete block while that many batches are in flight.
+func WithWriterConcurrency(concurrency int) BatchWriterOption {
+	return func(w *BatchWriter) {
+		if concurrency > 0 {
+			w.concurrency = concurrency
+		}
+	}
+}
+
+// NewBatchWriter returns a BatchWriter writing items to the client's table. The writes use ctx,
+// so canceling it makes the pending and the following writes fail.
+// Close must be called to write the last requests and get the outcome of the writes.
+//
+// Example:
+//
+//	w := db.NewBatchWriter(ctx, WithWriterMaxLatency(50*time.Millisecond))
+//	for d := range items {
+//		if err := w.Put(d); err != nil {
+//			return err
+//		}
+//	}
+//	result, err := w.Close()
+func (c *Client) NewBatchWriter(ctx context.Context, opts ...BatchWriterOption) *BatchWriter {
+	w := &BatchWriter{
+		c:           c,
+		ctx:         ctx,
+		maxLatency:  defaultWriterMaxLatency,
+		concurrency: defaultWriterConcurrency,
+		keys:        make(map[string]int),
+		result:      newBatchResult(opBatchWrite),
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	w.g.SetLimit(w.concurrency)
+	return w
+}
+
+// Put adds a put request for the item. It returns an error if the item is invalid or the writer is closed;
+// the failures of the write itself are reported by Close.
+func (w *BatchWriter) Put(item ItemData) error {
+	if err := item.Validate(); err != nil {
+		return validationError().method(opBatchWrite).wrap(err)
+	}
+	av, err := w.c.marshalItem(w.ctx, item)
+	if err != nil {
+		return dynamoError().method(opBatchWrite).wrap(err)
+	}
+	if err = w.c.checkItemSize(av); err == nil {
+		err = w.add(w.c.tableName, w.c.primaryKey(av), types.WriteRequest{PutRequest: &types.PutRequest{Item: av}})
+	}
+	if err != nil {
+		// the item won't be written, so the blobs offloaded for it are orphans
+		ctx, cancel := cleanupContext(w.ctx)
+		defer cancel()
+		return dynamoError().method(opBatchWrite).wrap(joinCleanupError(err, w.c.deleteBlobs(ctx, blobKeys(av))))
+	}
+	return nil
+}
+
+// Delete adds a delete request for the item with the key set on key.
+// It returns an error if the key is invalid or the writer is closed; the failures of the write itself are reported by Close.
+//
+// Example:
+//
+//	err := w.Delete(db.PK(PK).SK(Equal(SK)))
+func (w *BatchWriter) Delete(key *Item) error {
+	if key.err != nil {
+		return key.err
+	}
+	if key.c != w.c {
+		return validationError().method(opBatchWrite).message("item doesn't belong to the client of the writer")
+	}
+	if len(key.key) == 0 {
+		return validationError().method(opBatchWrite).message(errMissingPartitionKey)
+	}
+	if err := w.add(w.c.tableName, key.key, types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: key.key}}); err != nil {
+		return dynamoError().method(opBatchWrite).wrap(err)
+	}
+	return nil
+}
+
+// Close writes the pending requests, waits for all the writes and returns their outcome.
+// The error is the one of BatchResult.Err.
+func (w *BatchWriter) Close() (*BatchResult, error) {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		if w.timer != nil {
+			w.timer.Stop()
+		}
+		w.flush()
+	}
+	w.mu.Unlock()
+
+	_ = w.g.Wait()
+	return w.result, w.result.Err()
+}
+
+// add adds the write request to the pending batch, replacing the pending request with the same key,
+// and writes the batch if it is full.
+func (w *BatchWriter) add(table string, key map[string]types.AttributeValue, request types.WriteRequest) error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return errors.New("batch writer is closed")
+	}
+
+	id := cacheKey(table, key)
+	if n, ok := w.keys[id]; ok {
+		replaced := w.pending[n].request
+		w.pending[n].request = request
+		w.mu.Unlock()
+		w.discard(replaced, request)
+		return nil
+	}
+	defer w.mu.Unlock()
+	w.keys[id] = len(w.pending)
+	w.pending = append(w.pending, batchWrite{table: table, request: request})
+
+	switch {
+	case len(w.pending) >= writeBatchSize:
+		w.timer.Stop()
+		w.flush()
+	case len(w.pending) == 1:
+		w.timer = time.AfterFunc(w.maxLatency, w.flushTimer)
+	}
+	return nil
+}
+
+// discard deletes the blobs offloaded for the item of a put request replaced by request, which won't be written.
+// The blobs are deleted with cleanupContext, even if the context of the writer is canceled.
+// The error of the cleanup is reported by Close.
+func (w *BatchWriter) discard(replaced, request types.WriteRequest) {
+	if replaced.PutRequest == nil {
+		return
+	}
+	var keep []map[string]types.AttributeValue
+	if request.PutRequest != nil {
+		keep = append(keep, request.PutRequest.Item)
+	}
+	ctx, cancel := cleanupContext(w.ctx)
+	defer cancel()
+	if err := w.c.deleteBlobs(ctx, blobKeys(replaced.PutRequest.Item), keep...); err != nil {
+		w.result.addError(dynamoError().method(opBatchWrite).wrap(err))
+	}
+}
+
+// flushTimer writes the pending batch once it has waited for the maximum latency.
+func (w *BatchWriter) flushTimer() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.closed {
+		w.flush()
+	}
+}
+
+// flush starts writing the pending batch, waiting while the maximum number of batches are in flight.
+// It must be called with the lock held, so that no batch is started once Close waits for the writes.
+func (w *BatchWriter) flush() {
+	if len(w.pending) == 0 {
+		return
+	}
+	batch := make(map[string][]types.WriteRequest)
+	for _, p := range w.pending {
+		batch[p.table] = append(batch[p.table], p.request)
+	}
+	w.pending = nil
+	w.keys = make(map[string]int)
+
+	w.g.Go(func() error {
+		return processBatchWrite(w.ctx, opBatchWrite, batchTables{def: w.c}, batch, w.result)
+	})
+}
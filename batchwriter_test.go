@@ -0,0 +1,85 @@
+package dygo
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_batch_writer(t *testing.T) {
+	fake := newFakeDynamoDB()
+	db := newFakeClient(t, fake)
+
+	var mu sync.Mutex
+	var inFlight, maxInFlight int
+	fake.hook = func(op string, req map[string]json.RawMessage) (int, any) {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		return 0, nil
+	}
+
+	w := db.NewBatchWriter(context.Background(), WithWriterConcurrency(2), WithWriterMaxLatency(time.Hour))
+	pks := make([]string, 60)
+	for n := range pks {
+		pks[n] = newPK("room")
+		assert.NoError(t, w.Put(dataItem{PK: pks[n], SK: "current", EntityType: "room", LogicalName: "old"}))
+	}
+	// the requests replace the pending requests with the same key in the last batch
+	assert.NoError(t, w.Put(dataItem{PK: pks[55], SK: "current", EntityType: "room", LogicalName: "new"}))
+	assert.NoError(t, w.Delete(db.PK(pks[59]).SK(Equal("current"))))
+	assert.Error(t, w.Put(dataItem{PK: newPK("room"), SK: "current", EntityType: "table1"}))
+
+	result, err := w.Close()
+	assert.NoError(t, err)
+	assert.Len(t, result.Succeeded, 60)
+	assert.Equal(t, 3, fake.callCount("BatchWriteItem"))
+	assert.LessOrEqual(t, maxInFlight, 2)
+	for n, pk := range pks[:59] {
+		item := fake.get("test-table-1", map[string]types.AttributeValue{
+			"_partition_key": &types.AttributeValueMemberS{Value: pk},
+			"_sort_key":      &types.AttributeValueMemberS{Value: "current"},
+		})
+		want := "old"
+		if n == 55 {
+			want = "new"
+		}
+		assert.Equal(t, &types.AttributeValueMemberS{Value: want}, item["logical_name"])
+	}
+	assert.Nil(t, fake.get("test-table-1", map[string]types.AttributeValue{
+		"_partition_key": &types.AttributeValueMemberS{Value: pks[59]},
+		"_sort_key":      &types.AttributeValueMemberS{Value: "current"},
+	}))
+	assert.Error(t, w.Put(dataItem{PK: newPK("room"), SK: "current", EntityType: "room"}))
+}
+
+func Test_batch_writer_max_latency(t *testing.T) {
+	fake := newFakeDynamoDB()
+	db := newFakeClient(t, fake)
+
+	w := db.NewBatchWriter(context.Background(), WithWriterMaxLatency(10*time.Millisecond))
+	pk := newPK("room")
+	assert.NoError(t, w.Put(dataItem{PK: pk, SK: "current", EntityType: "room"}))
+	key := map[string]types.AttributeValue{
+		"_partition_key": &types.AttributeValueMemberS{Value: pk},
+		"_sort_key":      &types.AttributeValueMemberS{Value: "current"},
+	}
+	assert.Eventually(t, func() bool { return fake.get("test-table-1", key) != nil }, time.Second, 5*time.Millisecond)
+
+	result, err := w.Close()
+	assert.NoError(t, err)
+	assert.Equal(t, []BatchKey{{Table: "test-table-1", Key: key}}, result.Succeeded)
+	assert.Equal(t, 1, fake.callCount("BatchWriteItem"))
+}
@@ -0,0 +1,137 @@
+package dygo
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// blindIndexValue computes the deterministic blind index of the plaintext value v of the given attribute.
+// The attribute name is part of the HMAC input, so equal values of different attributes produce different indexes.
+func blindIndexValue(key []byte, attributeName string, v types.AttributeValue) (string, error) {
+	if len(key) == 0 {
+		return "", errors.New("blind index key is not configured")
+	}
+	data, err := marshalDynamoDBJSON(v)
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(attributeName))
+	mac.Write([]byte{0})
+	mac.Write(data)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+// blindIndexOf returns the value of the blind index for the given plaintext value of a registered attribute.
+func (c *Client) blindIndexOf(attributeName string, value any) (string, error) {
+	av, ok := value.(types.AttributeValue)
+	if !ok {
+		var err error
+		av, err = attributevalue.Marshal(value)
+		if err != nil {
+			return "", err
+		}
+	}
+	return blindIndexValue(c.blindIndexKey, attributeName, av)
+}
+
+// blindIndexSource returns the plaintext attribute for which indexAttributeName holds the blind index.
+func (c *Client) blindIndexSource(indexAttributeName string) (string, bool) {
+	for attributeName, indexName := range c.blindIndexes {
+		if indexName == indexAttributeName {
+			return attributeName, true
+		}
+	}
+	return "", false
+}
+
+// BlindEqual returns a FilterFunc that filters the items whose attribute with a blind index, registered with WithBlindIndex,
+// equals value. The filter compares the blind index attribute with the HMAC of value, so the plaintext is never sent.
+// Filter with KeyEqual on the attribute is rewritten the same way; BlindEqual can also be combined with other filters
+// with And, Or, Not and On.
+//
+// Example:
+//
+//	err = db.
+//		PK("pk").
+//		Filter("email", db.BlindEqual("someone@example.com")).
+//		Query(context.Background()).
+//		Unmarshal(&data, []string{"user"}).
+//		Run()
+func (c *Client) BlindEqual(value any) FilterFunc {
+	return func(keyName string) expression.ConditionBuilder {
+		indexName, ok := c.blindIndexes[keyName]
+		if !ok {
+			return pathName(keyName).Equal(expression.Value(valueError{fmt.Errorf("attribute %q has no blind index", keyName)}))
+		}
+		index, err := c.blindIndexOf(keyName, value)
+		if err != nil {
+			return pathName(keyName).Equal(expression.Value(valueError{fmt.Errorf("attribute %q: %w", keyName, err)}))
+		}
+		return expression.Name(indexName).Equal(expression.Value(index))
+	}
+}
+
+// equalFilter returns the FilterFunc of an equality on the attribute: BlindEqual if the attribute has a blind index,
+// KeyEqual otherwise.
+func (c *Client) equalFilter(attributeName string, value any) FilterFunc {
+	if _, ok := c.blindIndexes[attributeName]; ok {
+		return c.BlindEqual(value)
+	}
+	return KeyEqual(value)
+}
+
+// filterCondition returns the condition of the FilterFunc for the attribute.
+// KeyEqual filters on attributes with a registered blind index are rewritten to match the blind index instead.
+func (c *Client) filterCondition(attributeName string, f FilterFunc) (expression.ConditionBuilder, error) {
+	if attributeName != "" {
+		if _, err := attributePath(attributeName); err != nil {
+			return expression.ConditionBuilder{}, err
+		}
+	}
+	indexName, ok := c.blindIndexes[attributeName]
+	if !ok {
+		return c.checkBlindIndexes(f(attributeName))
+	}
+
+	condition := f(attributeName)
+	expr, err := buildExpression(expression.NewBuilder().WithFilter(condition))
+	if err != nil {
+		return expression.ConditionBuilder{}, err
+	}
+	if expr.Filter() == nil || *expr.Filter() != "#0 = :0" || expr.Names()["#0"] != attributeName {
+		// conditions built with BlindEqual already refer to the blind index only
+		return c.checkBlindIndexes(condition)
+	}
+	value, err := blindIndexValue(c.blindIndexKey, attributeName, expr.Values()[":0"])
+	if err != nil {
+		return expression.ConditionBuilder{}, fmt.Errorf("attribute %q: %w", attributeName, err)
+	}
+	return expression.Name(indexName).Equal(expression.Value(value)), nil
+}
+
+// checkBlindIndexes rejects a condition that refers to an attribute with a blind index other than
+// a KeyEqual filter set directly on the attribute, since the stored value is encrypted and can only be matched
+// through its blind index.
+func (c *Client) checkBlindIndexes(condition expression.ConditionBuilder) (expression.ConditionBuilder, error) {
+	if len(c.blindIndexes) == 0 {
+		return condition, nil
+	}
+	expr, err := buildExpression(expression.NewBuilder().WithFilter(condition))
+	if err != nil {
+		return expression.ConditionBuilder{}, err
+	}
+	for _, name := range expr.Names() {
+		if _, ok := c.blindIndexes[name]; ok {
+			return expression.ConditionBuilder{}, fmt.Errorf("attribute %q has a blind index and must be filtered on its own with KeyEqual or with BlindEqual", name)
+		}
+	}
+	return condition, nil
+}
@@ -0,0 +1,112 @@
+package dygo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type indexedItem struct {
+	PK    string `dynamodbav:"_partition_key"`
+	Email string `dynamodbav:"email" dygo:"encrypt"`
+}
+
+func (s indexedItem) Validate() error {
+	return nil
+}
+
+func newBlindIndexClient(t *testing.T) *Client {
+	c := &Client{
+		tableName:    "test-table-1",
+		partitionKey: "_partition_key",
+		keyProvider:  newTestKeyProvider(t, "k1"),
+	}
+	for _, opt := range []Option{
+		WithBlindIndexKey([]byte("blind-index-key")),
+		WithBlindIndex("email", "email_bidx"),
+		WithGSI("email-index", "email_bidx", ""),
+	} {
+		if err := opt(c); err != nil {
+			t.Fatalf("unexpected error : %v", err)
+		}
+	}
+	return c
+}
+
+func Test_blind_index_written_and_filtered(t *testing.T) {
+	c := newBlindIndexClient(t)
+
+	av, err := c.marshalItem(context.Background(), indexedItem{PK: "usr-1", Email: "someone@example.com"})
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	index, ok := av["email_bidx"].(*types.AttributeValueMemberS)
+	if !ok {
+		t.Fatalf("expected blind index attribute, got : %v", av["email_bidx"])
+	}
+	assert.IsType(t, &types.AttributeValueMemberB{}, av["email"])
+
+	for _, f := range []FilterFunc{KeyEqual("someone@example.com"), c.BlindEqual("someone@example.com")} {
+		item := c.PK("usr-1").Filter("email", f)
+		if item.err != nil {
+			t.Fatalf("unexpected error : %v", item.err)
+		}
+		expr, err := expression.NewBuilder().WithFilter(item.filter).Build()
+		if err != nil {
+			t.Fatalf("unexpected error : %v", err)
+		}
+		assert.Equal(t, "#0 = :0", *expr.Filter())
+		assert.Equal(t, "email_bidx", expr.Names()["#0"])
+		assert.Equal(t, index, expr.Values()[":0"])
+	}
+}
+
+func Test_blind_index_gsi_lookup(t *testing.T) {
+	c := newBlindIndexClient(t)
+	want, err := c.blindIndexOf("email", "someone@example.com")
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+
+	item := c.GSI("email-index", "someone@example.com", nil)
+	if item.err != nil {
+		t.Fatalf("unexpected error : %v", item.err)
+	}
+	expr, err := expression.NewBuilder().WithKeyCondition(item.keyCondition).Build()
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	assert.Equal(t, &types.AttributeValueMemberS{Value: want}, expr.Values()[":0"])
+}
+
+func Test_blind_index_rejects_non_equality_filters(t *testing.T) {
+	c := newBlindIndexClient(t)
+	item := c.PK("usr-1").Filter("email", KeyBeginsWith("someone"))
+	assert.Error(t, item.err)
+	// BlindEqual requires a blind index
+	item = c.PK("usr-1").Filter("phone", c.BlindEqual("0123"))
+	assert.Error(t, item.err)
+
+	// equality in filter strings goes through the blind index
+	item = c.PK("usr-1").FilterString("email = 'someone@example.com'")
+	assert.NoError(t, item.err)
+
+	// values of different attributes never share an index
+	a, _ := c.blindIndexOf("email", "x")
+	b, _ := c.blindIndexOf("phone", "x")
+	assert.NotEqual(t, a, b)
+}
+
+func Test_blind_index_requires_key(t *testing.T) {
+	_, err := NewClient(
+		WithTableName("test-table-1"),
+		WithRegion("ap-northeast-1"),
+		WithPartitionKey("_partition_key"),
+		WithBlindIndex("email", "email_bidx"),
+	)
+	assert.True(t, errors.Is(err, ErrValidation), err)
+}
@@ -0,0 +1,250 @@
+package dygo
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// BlobStore stores attribute values offloaded from items that exceed the item size limit.
+// Implementations are expected to behave like an object store such as S3.
+type BlobStore interface {
+	Put(ctx context.Context, key string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// FileBlobStore is a BlobStore keeping blobs as files in a local directory.
+// It is intended for tests and local development.
+type FileBlobStore struct {
+	dir string
+}
+
+// NewFileBlobStore returns a BlobStore storing blobs in dir, creating the directory if needed.
+func NewFileBlobStore(dir string) (*FileBlobStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileBlobStore{dir: dir}, nil
+}
+
+// Put writes the blob stored under key.
+func (s *FileBlobStore) Put(ctx context.Context, key string, data []byte) error {
+	tmp, err := os.CreateTemp(s.dir, ".blob-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(key))
+}
+
+// Get reads the blob stored under key.
+func (s *FileBlobStore) Get(ctx context.Context, key string) ([]byte, error) {
+	return os.ReadFile(s.path(key))
+}
+
+// Delete removes the blob stored under key. Deleting a missing blob is not an error.
+func (s *FileBlobStore) Delete(ctx context.Context, key string) error {
+	err := os.Remove(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// path returns the file path of the blob stored under key.
+func (s *FileBlobStore) path(key string) string {
+	return filepath.Join(s.dir, base64.RawURLEncoding.EncodeToString([]byte(key)))
+}
+
+// offloadMinSize returns the item size in bytes from which attributes tagged for offloading are moved to the blob store.
+func (c *Client) offloadMinSize() int {
+	if c.offloadThreshold <= 0 {
+		return maxItemSize
+	}
+	return c.offloadThreshold
+}
+
+// offloadAttributes moves the attributes tagged for offloading to the blob store if the item is too large,
+// replacing each of them with a pointer envelope: magic | 'p' | blob key.
+func (c *Client) offloadAttributes(ctx context.Context, av map[string]types.AttributeValue, fields []fieldOptions) error {
+	if ItemSize(av) <= c.offloadMinSize() {
+		return nil
+	}
+	for _, f := range fields {
+		v, ok := av[f.attributeName]
+		if !ok || !f.offload {
+			continue
+		}
+		if c.blobStore == nil {
+			return fmt.Errorf("attribute %q is tagged for offloading but no blob store is configured", f.attributeName)
+		}
+
+		var data []byte
+		if b, ok := v.(*types.AttributeValueMemberB); ok && isEnvelope(b.Value) {
+			data = b.Value
+		} else {
+			var err error
+			if data, err = marshalDynamoDBJSON(v); err != nil {
+				return fmt.Errorf("attribute %q: %w", f.attributeName, err)
+			}
+		}
+
+		key, err := c.newBlobKey(av, f.attributeName)
+		if err != nil {
+			return fmt.Errorf("attribute %q: %w", f.attributeName, err)
+		}
+		if err := c.blobStore.Put(ctx, key, data); err != nil {
+			return fmt.Errorf("attribute %q: %w", f.attributeName, err)
+		}
+
+		pointer := make([]byte, 0, len(envelopeMagic)+1+len(key))
+		pointer = append(pointer, envelopeMagic...)
+		pointer = append(pointer, envelopePointer)
+		av[f.attributeName] = &types.AttributeValueMemberB{Value: append(pointer, key...)}
+	}
+	return nil
+}
+
+// newBlobKey returns a unique blob key for an attribute of the item.
+// Keys are unique per write, so a failed write never overwrites the blob of an existing item.
+func (c *Client) newBlobKey(av map[string]types.AttributeValue, attributeName string) (string, error) {
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+	keyHash := sha256.Sum256([]byte(keyString(c.primaryKey(av))))
+	return fmt.Sprintf("%s/%s/%s/%s", c.tableName, hex.EncodeToString(keyHash[:]), attributeName, hex.EncodeToString(suffix)), nil
+}
+
+// fetchBlob returns the blob referenced by the payload of a pointer envelope.
+func (c *Client) fetchBlob(ctx context.Context, payload []byte) ([]byte, error) {
+	if c.blobStore == nil {
+		return nil, errors.New("offloaded attribute found but no blob store is configured")
+	}
+	return c.blobStore.Get(ctx, string(payload))
+}
+
+// blobKeys returns the keys of all blobs referenced by the item.
+func blobKeys(item map[string]types.AttributeValue) []string {
+	var keys []string
+	for _, v := range item {
+		b, ok := v.(*types.AttributeValueMemberB)
+		if ok && isEnvelope(b.Value) && b.Value[len(envelopeMagic)] == envelopePointer {
+			keys = append(keys, string(b.Value[len(envelopeMagic)+1:]))
+		}
+	}
+	return keys
+}
+
+// deleteBlobs deletes the given blobs, excluding the ones still referenced by keep.
+func (c *Client) deleteBlobs(ctx context.Context, keys []string, keep ...map[string]types.AttributeValue) error {
+	if c.blobStore == nil {
+		return nil
+	}
+	kept := make(map[string]bool)
+	for _, item := range keep {
+		for _, key := range blobKeys(item) {
+			kept[key] = true
+		}
+	}
+	for _, key := range keys {
+		if kept[key] {
+			continue
+		}
+		if err := c.blobStore.Delete(ctx, key); err != nil {
+			return fmt.Errorf("failed to delete blob %q: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// blobCleanupTimeout bounds the cleanup of orphan blobs run by cleanupContext.
+const blobCleanupTimeout = 30 * time.Second
+
+// cleanupContext returns the context of the cleanup of the blobs of an operation that stopped.
+// It keeps the values of ctx but not its cancellation, so that the blobs are deleted even when ctx was canceled,
+// and it is limited to blobCleanupTimeout instead.
+func cleanupContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(detachedContext{ctx}, blobCleanupTimeout)
+}
+
+// detachedContext is a context with the values of its parent, which is never canceled.
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+// joinCleanupError returns the error of an operation, along with the error of the cleanup of the blobs it offloaded.
+// The error of the operation keeps its kind, such as ErrConditionFailed.
+func joinCleanupError(err error, cleanupErr error) error {
+	if cleanupErr == nil {
+		return err
+	}
+	return errors.Join(err, cleanupErr)
+}
+
+// fetchBlobKeys returns the keys of the blobs referenced by the stored items targeted by the write requests.
+// It is used to clean up blobs of items that are deleted or overwritten by a batch write.
+func (c *Client) fetchBlobKeys(ctx context.Context, batch map[string][]types.WriteRequest) ([]string, error) {
+	if c.blobStore == nil {
+		return nil, nil
+	}
+	request := make(map[string]types.KeysAndAttributes)
+	for table, writes := range batch {
+		keys := make([]map[string]types.AttributeValue, 0, len(writes))
+		for _, w := range writes {
+			switch {
+			case w.DeleteRequest != nil:
+				keys = append(keys, w.DeleteRequest.Key)
+			case w.PutRequest != nil:
+				keys = append(keys, c.primaryKey(w.PutRequest.Item))
+			}
+		}
+		request[table] = types.KeysAndAttributes{Keys: keys}
+	}
+
+	var keys []string
+	paginator := newBatchGetItemPaginator(c.client, &dynamodb.BatchGetItemInput{RequestItems: request})
+	for paginator.hasMorePages() {
+		page, err := paginator.nextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, items := range page.Responses {
+			for _, item := range items {
+				keys = append(keys, blobKeys(item)...)
+			}
+		}
+	}
+	return keys, nil
+}
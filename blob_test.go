@@ -0,0 +1,270 @@
+package dygo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type offloadedItem struct {
+	PK   string `dynamodbav:"_partition_key"`
+	SK   string `dynamodbav:"_sort_key"`
+	Body string `dynamodbav:"body" dygo:"compress,offload"`
+	Raw  string `dynamodbav:"raw" dygo:"offload"`
+}
+
+func (o offloadedItem) Validate() error {
+	return nil
+}
+
+func newBlobClient(t *testing.T, threshold int) (*Client, string) {
+	dir := t.TempDir()
+	store, err := NewFileBlobStore(dir)
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	c := &Client{tableName: "test-table-1", partitionKey: "_partition_key", sortKey: "_sort_key"}
+	if err := WithBlobStore(store, threshold)(c); err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	return c, dir
+}
+
+func countFiles(t *testing.T, dir string) int {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	return len(entries)
+}
+
+func Test_blob_offload_round_trip(t *testing.T) {
+	c, dir := newBlobClient(t, 1024)
+	in := offloadedItem{
+		PK:   "doc-1",
+		SK:   "current",
+		Body: strings.Repeat("lorem ipsum ", 1000),
+		Raw:  strings.Repeat("r", 2048),
+	}
+
+	av, err := c.marshalItem(context.Background(), in)
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	assert.Len(t, blobKeys(av), 2)
+	assert.Equal(t, 2, countFiles(t, dir))
+	assert.Less(t, ItemSize(av), 1024)
+
+	stored := make(map[string]types.AttributeValue, len(av))
+	for k, v := range av {
+		stored[k] = v
+	}
+	if err := c.decodeItem(context.Background(), av); err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	var out offloadedItem
+	if err := attributevalue.UnmarshalMap(av, &out); err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	assert.Equal(t, in, out)
+
+	if err := c.deleteBlobs(context.Background(), blobKeys(stored)); err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	assert.Equal(t, 0, countFiles(t, dir))
+}
+
+func Test_blob_offload_small_items_stay_inline(t *testing.T) {
+	c, dir := newBlobClient(t, 0)
+	av, err := c.marshalItem(context.Background(), offloadedItem{PK: "doc-1", SK: "current", Raw: "small"})
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "small"}, av["raw"])
+	assert.Equal(t, 0, countFiles(t, dir))
+}
+
+func Test_blob_offload_keeps_referenced_blobs(t *testing.T) {
+	c, dir := newBlobClient(t, 1)
+	av, err := c.marshalItem(context.Background(), offloadedItem{PK: "doc-1", SK: "current", Raw: "value"})
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	if err := c.deleteBlobs(context.Background(), blobKeys(av), av); err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	assert.Equal(t, 2, countFiles(t, dir))
+
+	// reading an offloaded attribute requires a blob store
+	assert.Error(t, (&Client{}).decodeItem(context.Background(), av))
+}
+
+func Test_blob_batch_upsert_offloads_when_run(t *testing.T) {
+	fake := newFakeDynamoDB()
+	c := newFakeClient(t, fake)
+	dir := t.TempDir()
+	store, err := NewFileBlobStore(dir)
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	if err := WithBlobStore(store, 1)(c); err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+
+	// items are only offloaded when the batch runs
+	batch := new(Item)
+	c.Item(offloadedItem{PK: "doc-1", SK: "current", Raw: "value"}).AddBatchUpsertItem(batch)
+	assert.Equal(t, 0, countFiles(t, dir))
+
+	// the blobs of the items whose write failed are deleted
+	fake.hook = func(op string, req map[string]json.RawMessage) (int, any) {
+		if op == "BatchWriteItem" {
+			return http.StatusBadRequest, fakeError("ValidationException", "invalid item")
+		}
+		return 0, nil
+	}
+	_, err = batch.BatchUpsertItemResult(context.Background(), 1)
+	assert.True(t, errors.Is(err, ErrValidation), err)
+	assert.Equal(t, 0, countFiles(t, dir))
+
+	fake.hook = nil
+	if _, err := batch.BatchUpsertItemResult(context.Background(), 1); err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	assert.Equal(t, 2, countFiles(t, dir))
+}
+
+func Test_blob_write_batch_offloads_when_written(t *testing.T) {
+	fake := newFakeDynamoDB()
+	c := newFakeClient(t, fake)
+	dir := t.TempDir()
+	store, err := NewFileBlobStore(dir)
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	if err := WithBlobStore(store, 1)(c); err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+
+	// the put replaced by the second one is never offloaded
+	batch := NewWriteBatch().
+		Put(c.Item(offloadedItem{PK: "doc-1", SK: "current", Raw: "first"})).
+		Put(c.Item(offloadedItem{PK: "doc-1", SK: "current", Raw: "second"}))
+	assert.Equal(t, 0, countFiles(t, dir))
+
+	result, err := batch.Write(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	assert.Len(t, result.Succeeded, 1)
+	assert.Equal(t, 2, countFiles(t, dir))
+}
+
+func Test_blob_batch_writer_deletes_blobs_of_unwritten_puts(t *testing.T) {
+	fake := newFakeDynamoDB()
+	c := newFakeClient(t, fake)
+	dir := t.TempDir()
+	store, err := NewFileBlobStore(dir)
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	if err := WithBlobStore(store, 1)(c); err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+
+	w := c.NewBatchWriter(context.Background(), WithWriterMaxLatency(time.Hour))
+	// the blobs of the replaced puts are deleted
+	assert.NoError(t, w.Put(offloadedItem{PK: "doc-1", SK: "current", Raw: "first"}))
+	assert.NoError(t, w.Put(offloadedItem{PK: "doc-1", SK: "current", Raw: "second"}))
+	assert.Equal(t, 2, countFiles(t, dir))
+	assert.NoError(t, w.Put(offloadedItem{PK: "doc-2", SK: "current", Raw: "first"}))
+	assert.NoError(t, w.Delete(c.PK("doc-2").SK(Equal("current"))))
+	assert.Equal(t, 2, countFiles(t, dir))
+
+	result, err := w.Close()
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	assert.Len(t, result.Succeeded, 2)
+
+	// the blobs of a put rejected by the closed writer are deleted
+	assert.Error(t, w.Put(offloadedItem{PK: "doc-3", SK: "current", Raw: "late"}))
+	assert.Equal(t, 2, countFiles(t, dir))
+}
+
+// contextBlobStore is a FileBlobStore whose deletions fail once their context is done, as with a remote store.
+type contextBlobStore struct {
+	*FileBlobStore
+}
+
+func (s contextBlobStore) Delete(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.FileBlobStore.Delete(ctx, key)
+}
+
+func Test_blob_batch_write_deletes_blobs_of_skipped_puts(t *testing.T) {
+	fake := newFakeDynamoDB()
+	c := newFakeClient(t, fake)
+	dir := t.TempDir()
+	store, err := NewFileBlobStore(dir)
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	if err := WithBlobStore(contextBlobStore{store}, 1)(c); err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w := c.NewBatchWriter(ctx, WithWriterMaxLatency(time.Hour))
+	assert.NoError(t, w.Put(offloadedItem{PK: "doc-1", SK: "current", Raw: "first"}))
+	assert.Equal(t, 2, countFiles(t, dir))
+	cancel()
+
+	// the batch is skipped and its blobs are deleted despite the canceled context
+	result, err := w.Close()
+	assert.True(t, errors.Is(err, context.Canceled), err)
+	assert.Len(t, result.Skipped, 1)
+	assert.Equal(t, 0, countFiles(t, dir))
+}
+
+func Test_blob_batch_writer_deletes_blobs_after_cancel(t *testing.T) {
+	fake := newFakeDynamoDB()
+	c := newFakeClient(t, fake)
+	dir := t.TempDir()
+	store, err := NewFileBlobStore(dir)
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	if err := WithBlobStore(contextBlobStore{store}, 1)(c); err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w := c.NewBatchWriter(ctx, WithWriterMaxLatency(time.Hour))
+	assert.NoError(t, w.Put(offloadedItem{PK: "doc-1", SK: "current", Raw: "first"}))
+	cancel()
+
+	// the blobs of the replaced put are deleted despite the canceled context
+	assert.NoError(t, w.Put(offloadedItem{PK: "doc-1", SK: "current", Raw: "second"}))
+	assert.Equal(t, 2, countFiles(t, dir))
+
+	result, err := w.Close()
+	assert.True(t, errors.Is(err, context.Canceled), err)
+	assert.Len(t, result.Skipped, 1)
+	assert.Equal(t, 0, countFiles(t, dir))
+
+	// as are the blobs of a put rejected by the closed writer
+	assert.Error(t, w.Put(offloadedItem{PK: "doc-2", SK: "current", Raw: "late"}))
+	assert.Equal(t, 0, countFiles(t, dir))
+}
@@ -0,0 +1,307 @@
+package dygo
+
+import (
+	"container/list"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Cache is a read-through cache for items read by GetItem, GetAuthorizedItem and BatchGetItem.
+// Items are stored decoded, i.e. after decryption, decompression and rehydration of offloaded attributes.
+// Implementations must be safe for concurrent use.
+type Cache interface {
+	Get(key string) (map[string]types.AttributeValue, bool)
+	Set(key string, item map[string]types.AttributeValue)
+	Delete(key string)
+}
+
+// LRUCache is an in-process Cache that evicts the least recently used items once it is full,
+// and expires items after a fixed time to live.
+type LRUCache struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	entries  map[string]*list.Element
+	order    *list.List
+	now      func() time.Time
+}
+
+type lruEntry struct {
+	key     string
+	item    map[string]types.AttributeValue
+	expires time.Time
+}
+
+// NewLRUCache returns a Cache holding up to capacity items, each for at most ttl.
+// A ttl <= 0 keeps items until they are evicted or invalidated.
+//
+// Example:
+//
+//	db, err := NewClient(
+//		...
+//		WithCache(NewLRUCache(10000, time.Minute)),
+//	)
+func NewLRUCache(capacity int, ttl time.Duration) *LRUCache {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &LRUCache{
+		capacity: capacity,
+		ttl:      ttl,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+		now:      time.Now,
+	}
+}
+
+// Get returns the item stored under key, if present and not expired.
+func (l *LRUCache) Get(key string) (map[string]types.AttributeValue, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	elem, ok := l.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*lruEntry)
+	if l.ttl > 0 && l.now().After(entry.expires) {
+		l.order.Remove(elem)
+		delete(l.entries, key)
+		return nil, false
+	}
+	l.order.MoveToFront(elem)
+	return entry.item, true
+}
+
+// Set stores the item under key, evicting the least recently used item if the cache is full.
+func (l *LRUCache) Set(key string, item map[string]types.AttributeValue) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entry := &lruEntry{key: key, item: item, expires: l.now().Add(l.ttl)}
+	if elem, ok := l.entries[key]; ok {
+		elem.Value = entry
+		l.order.MoveToFront(elem)
+		return
+	}
+	l.entries[key] = l.order.PushFront(entry)
+	for l.order.Len() > l.capacity {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.entries, oldest.Value.(*lruEntry).key)
+	}
+}
+
+// Delete removes the item stored under key.
+func (l *LRUCache) Delete(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if elem, ok := l.entries[key]; ok {
+		l.order.Remove(elem)
+		delete(l.entries, key)
+	}
+}
+
+// Len returns the number of items in the cache, including expired items that were not evicted yet.
+func (l *LRUCache) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.order.Len()
+}
+
+// cacheKey returns the cache key of the item with the given primary key in the table.
+// Number key values are normalized, so a key matches the key of the item returned by DynamoDB.
+func cacheKey(table string, key map[string]types.AttributeValue) string {
+	names := make([]string, 0, len(key))
+	for name := range key {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	sb.WriteString(table)
+	for _, name := range names {
+		data, _ := marshalDynamoDBJSON(normalizeKeyValue(key[name]))
+		sb.WriteByte(0)
+		sb.WriteString(name)
+		sb.WriteByte(0)
+		sb.Write(data)
+	}
+	return sb.String()
+}
+
+// normalizeKeyValue returns the canonical form of a number, since DynamoDB returns numbers normalized,
+// e.g. 7 for 007 and 1.5 for 1.50. Other values are returned as is.
+func normalizeKeyValue(av types.AttributeValue) types.AttributeValue {
+	n, ok := av.(*types.AttributeValueMemberN)
+	if !ok {
+		return av
+	}
+	r, ok := new(big.Rat).SetString(n.Value)
+	if !ok {
+		return av
+	}
+	return &types.AttributeValueMemberN{Value: r.RatString()}
+}
+
+// copyItem returns a shallow copy of the item, so callers can't modify cached items.
+func copyItem(item map[string]types.AttributeValue) map[string]types.AttributeValue {
+	out := make(map[string]types.AttributeValue, len(item))
+	for k, v := range item {
+		out[k] = v
+	}
+	return out
+}
+
+// useCache reports whether reads of the item can be served from the cache.
+// Projected reads bypass the cache as they don't return complete items.
+func (i *Item) useCache() bool {
+	return i.c.cache != nil && !i.bypassCache && len(i.projection) == 0
+}
+
+// cachedItem returns the cached item stored under the key in the table.
+func (c *Client) cachedItem(table string, key map[string]types.AttributeValue) (map[string]types.AttributeValue, bool) {
+	item, ok := c.cache.Get(cacheKey(table, key))
+	if !ok {
+		return nil, false
+	}
+	return copyItem(item), true
+}
+
+// cacheFills keeps reads from filling the cache with items invalidated while the reads were in flight.
+// A read begins at the current generation, and every invalidation moves to the next generation and,
+// while reads are in flight, records it as the generation of the invalidated key.
+// A read fills the cache only with the items of the keys not invalidated since it began.
+type cacheFills struct {
+	mu          sync.Mutex
+	generation  uint64
+	reads       int
+	invalidated map[string]uint64
+}
+
+// newCacheFills returns the cacheFills of a client with a cache.
+func newCacheFills() *cacheFills {
+	return &cacheFills{invalidated: make(map[string]uint64)}
+}
+
+// beginCacheFill registers a read that may fill the cache and returns the generation it began at.
+// It must be called before the items are read, and endCacheFill once the read has filled the cache.
+func (c *Client) beginCacheFill() uint64 {
+	f := c.cacheFills
+	if f == nil {
+		return 0
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reads++
+	return f.generation
+}
+
+// endCacheFill unregisters a read registered with beginCacheFill.
+func (c *Client) endCacheFill() {
+	f := c.cacheFills
+	if f == nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reads--
+	if f.reads == 0 {
+		f.invalidated = make(map[string]uint64)
+	}
+}
+
+// cacheItem stores a decoded item under the key in the table, unless the key was invalidated
+// after the read that began at generation. Missing items are not cached.
+func (c *Client) cacheItem(generation uint64, table string, key map[string]types.AttributeValue, item map[string]types.AttributeValue) {
+	if c.cache == nil || len(item) == 0 {
+		return
+	}
+	id := cacheKey(table, key)
+	if f := c.cacheFills; f != nil {
+		// the lock orders the fill with the invalidations, so an invalidation either prevents it or removes the item
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if f.invalidated[id] > generation {
+			return
+		}
+	}
+	c.cache.Set(id, copyItem(item))
+}
+
+// invalidateCache removes the items with the given keys in the table from the cache.
+func (c *Client) invalidateCache(table string, keys ...map[string]types.AttributeValue) {
+	if c.cache == nil {
+		return
+	}
+	f := c.cacheFills
+	if f != nil {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+	}
+	for _, key := range keys {
+		id := cacheKey(table, key)
+		if f != nil {
+			f.generation++
+			if f.reads > 0 {
+				f.invalidated[id] = f.generation
+			}
+		}
+		c.cache.Delete(id)
+	}
+}
+
+// invalidateBatchCache removes the items targeted by the write requests from the cache.
+func (c *Client) invalidateBatchCache(batch map[string][]types.WriteRequest) {
+	if c.cache == nil {
+		return
+	}
+	for table, requests := range batch {
+		for _, r := range requests {
+			switch {
+			case r.DeleteRequest != nil:
+				c.invalidateCache(table, r.DeleteRequest.Key)
+			case r.PutRequest != nil:
+				c.invalidateCache(table, c.primaryKey(r.PutRequest.Item))
+			}
+		}
+	}
+}
+
+// cachedBatch splits the batch into the items found in the cache and a batch of the keys that must be fetched.
+// Tables read with a projection are always fetched.
+func (c *Client) cachedBatch(batch map[string]types.KeysAndAttributes) (map[string]types.KeysAndAttributes, []map[string]types.AttributeValue) {
+	remaining := make(map[string]types.KeysAndAttributes, len(batch))
+	var hits []map[string]types.AttributeValue
+	for table, keysAndAttributes := range batch {
+		if keysAndAttributes.ProjectionExpression != nil {
+			remaining[table] = keysAndAttributes
+			continue
+		}
+		var missing []map[string]types.AttributeValue
+		for _, key := range keysAndAttributes.Keys {
+			if item, ok := c.cachedItem(table, key); ok {
+				hits = append(hits, item)
+				continue
+			}
+			missing = append(missing, key)
+		}
+		if len(missing) > 0 {
+			keysAndAttributes.Keys = missing
+			remaining[table] = keysAndAttributes
+		}
+	}
+	return remaining, hits
+}
+
+// keyOf extracts from the item the attributes that make up the given key.
+func keyOf(item map[string]types.AttributeValue, key map[string]types.AttributeValue) map[string]types.AttributeValue {
+	out := make(map[string]types.AttributeValue, len(key))
+	for name := range key {
+		out[name] = item[name]
+	}
+	return out
+}
@@ -0,0 +1,177 @@
+package dygo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type cachedItem struct {
+	PK         string `dynamodbav:"_partition_key"`
+	SK         string `dynamodbav:"_sort_key"`
+	Name       string `dynamodbav:"name"`
+	authorized int
+	deny       bool
+}
+
+func (c *cachedItem) Authorize(ctx context.Context) error {
+	c.authorized++
+	if c.deny {
+		return errors.New("unauthorized")
+	}
+	return nil
+}
+
+func rawCachedItem(pk, name string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"_partition_key": &types.AttributeValueMemberS{Value: pk},
+		"_sort_key":      &types.AttributeValueMemberS{Value: "current"},
+		"name":           &types.AttributeValueMemberS{Value: name},
+	}
+}
+
+func newCachedClient() (*Client, *LRUCache) {
+	cache := NewLRUCache(10, time.Minute)
+	return &Client{
+		tableName:    "test-table-1",
+		partitionKey: "_partition_key",
+		sortKey:      "_sort_key",
+		cache:        cache,
+	}, cache
+}
+
+func Test_lru_cache_eviction_and_ttl(t *testing.T) {
+	now := time.Now()
+	cache := NewLRUCache(2, time.Second)
+	cache.now = func() time.Time { return now }
+
+	cache.Set("a", rawCachedItem("a", "a"))
+	cache.Set("b", rawCachedItem("b", "b"))
+	_, ok := cache.Get("a")
+	assert.True(t, ok)
+
+	// "b" is the least recently used item
+	cache.Set("c", rawCachedItem("c", "c"))
+	_, ok = cache.Get("b")
+	assert.False(t, ok)
+	assert.Equal(t, 2, cache.Len())
+
+	now = now.Add(2 * time.Second)
+	_, ok = cache.Get("a")
+	assert.False(t, ok)
+
+	cache.Delete("c")
+	assert.Equal(t, 0, cache.Len())
+}
+
+func Test_get_item_served_from_cache(t *testing.T) {
+	c, _ := newCachedClient()
+	key := map[string]types.AttributeValue{
+		"_partition_key": &types.AttributeValueMemberS{Value: "rm-1"},
+		"_sort_key":      &types.AttributeValueMemberS{Value: "current"},
+	}
+	c.cacheItem(0, c.tableName, key, rawCachedItem("rm-1", "cached"))
+
+	// no DynamoDB client is configured, so the item can only come from the cache
+	out := cachedItem{}
+	if err := c.PK("rm-1").SK(Equal("current")).GetAuthorizedItem(context.Background(), &out); err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	assert.Equal(t, "cached", out.Name)
+	assert.Equal(t, 1, out.authorized)
+
+	denied := cachedItem{deny: true}
+	err := c.PK("rm-1").SK(Equal("current")).GetAuthorizedItem(context.Background(), &denied)
+	assert.Error(t, err)
+
+	// cached items can't be modified through the returned maps
+	item, _ := c.cachedItem(c.tableName, key)
+	item["name"] = &types.AttributeValueMemberS{Value: "changed"}
+	item, _ = c.cachedItem(c.tableName, key)
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "cached"}, item["name"])
+
+	c.invalidateCache(c.tableName, key)
+	_, ok := c.cachedItem(c.tableName, key)
+	assert.False(t, ok)
+}
+
+func Test_batch_get_item_served_from_cache(t *testing.T) {
+	c, _ := newCachedClient()
+	batch := new(Item)
+	for _, pk := range []string{"rm-1", "rm-2"} {
+		c.cacheItem(0, c.tableName, map[string]types.AttributeValue{
+			"_partition_key": &types.AttributeValueMemberS{Value: pk},
+			"_sort_key":      &types.AttributeValueMemberS{Value: "current"},
+		}, rawCachedItem(pk, pk))
+		c.PK(pk).SK(Equal("current")).AddBatchGetItem(batch, true)
+	}
+
+	out, err := batch.BatchGetItem(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	assert.Len(t, out, 2)
+}
+
+func Test_cache_invalidated_by_batch_writes(t *testing.T) {
+	c, cache := newCachedClient()
+	key := map[string]types.AttributeValue{
+		"_partition_key": &types.AttributeValueMemberS{Value: "rm-1"},
+		"_sort_key":      &types.AttributeValueMemberS{Value: "current"},
+	}
+	c.cacheItem(0, c.tableName, key, rawCachedItem("rm-1", "cached"))
+	c.invalidateBatchCache(map[string][]types.WriteRequest{
+		c.tableName: {{PutRequest: &types.PutRequest{Item: rawCachedItem("rm-1", "new")}}},
+	})
+	assert.Equal(t, 0, cache.Len())
+}
+
+func Test_cache_not_filled_with_item_invalidated_during_read(t *testing.T) {
+	fake := newFakeDynamoDB()
+	c := newFakeClient(t, fake)
+	cache := NewLRUCache(10, 0)
+	if err := WithCache(cache)(c); err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	key := map[string]types.AttributeValue{
+		"_partition_key": &types.AttributeValueMemberS{Value: "rm-1"},
+		"_sort_key":      &types.AttributeValueMemberS{Value: "current"},
+	}
+	// a write of the item completes while the read of its previous version is in flight
+	fake.hook = func(op string, req map[string]json.RawMessage) (int, any) {
+		c.invalidateCache(c.tableName, key)
+		return http.StatusOK, map[string]any{"Item": encodeFakeItem(rawCachedItem("rm-1", "stale"))}
+	}
+
+	out := cachedItem{}
+	if err := c.PK("rm-1").SK(Equal("current")).GetAuthorizedItem(context.Background(), &out); err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	assert.Equal(t, "stale", out.Name)
+	assert.Equal(t, 0, cache.Len())
+
+	batch := new(Item)
+	c.PK("rm-1").SK(Equal("current")).AddBatchGetItem(batch, true)
+	fake.hook = func(op string, req map[string]json.RawMessage) (int, any) {
+		c.invalidateCache(c.tableName, key)
+		return http.StatusOK, map[string]any{"Responses": map[string]any{c.tableName: []any{encodeFakeItem(rawCachedItem("rm-1", "stale"))}}}
+	}
+	if _, err := batch.BatchGetItem(context.Background(), 1); err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	assert.Equal(t, 0, cache.Len())
+
+	// without a concurrent write, the read fills the cache
+	fake.hook = nil
+	fake.put(c.tableName, rawCachedItem("rm-1", "fresh"))
+	if err := c.PK("rm-1").SK(Equal("current")).GetAuthorizedItem(context.Background(), &out); err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	assert.Equal(t, 1, cache.Len())
+}
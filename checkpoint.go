@@ -0,0 +1,132 @@
+package dygo
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	checkpointAttribute = "sequence_number"
+	opGetCheckpoint     = "GetCheckpoint"
+	opSetCheckpoint     = "SetCheckpoint"
+)
+
+// CheckpointStore persists the position of a StreamConsumer in each shard of a stream,
+// so a restarted consumer resumes after the last processed record.
+// GetCheckpoint returns an empty sequence number for shards without a checkpoint.
+// Implementations must be safe for concurrent use.
+type CheckpointStore interface {
+	GetCheckpoint(ctx context.Context, streamArn, shardID string) (string, error)
+	SetCheckpoint(ctx context.Context, streamArn, shardID, sequenceNumber string) error
+}
+
+// checkpointRecordStore is implemented by the CheckpointStores whose checkpoints may be written to the streamed table,
+// so that the consumer recognizes the records of its own checkpoints.
+type checkpointRecordStore interface {
+	isCheckpointItem(streamArn string, key map[string]types.AttributeValue) bool
+}
+
+// MemoryCheckpointStore is a CheckpointStore keeping checkpoints in memory.
+// Checkpoints are lost when the process exits, so it is intended for tests and consumers that always start from the trim horizon.
+type MemoryCheckpointStore struct {
+	mu          sync.Mutex
+	checkpoints map[string]string
+}
+
+// NewMemoryCheckpointStore returns an empty MemoryCheckpointStore.
+func NewMemoryCheckpointStore() *MemoryCheckpointStore {
+	return &MemoryCheckpointStore{checkpoints: make(map[string]string)}
+}
+
+// GetCheckpoint returns the checkpoint of the shard.
+func (m *MemoryCheckpointStore) GetCheckpoint(ctx context.Context, streamArn, shardID string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.checkpoints[streamArn+"/"+shardID], nil
+}
+
+// SetCheckpoint stores the checkpoint of the shard.
+func (m *MemoryCheckpointStore) SetCheckpoint(ctx context.Context, streamArn, shardID, sequenceNumber string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.checkpoints[streamArn+"/"+shardID] = sequenceNumber
+	return nil
+}
+
+// DynamoDBCheckpointStore is a CheckpointStore keeping checkpoints as items in the table of a client.
+// The partition key of a checkpoint item is the prefix followed by the stream ARN, and the sort key is the shard ID.
+// For tables without a sort key, the shard ID is appended to the partition key with the key separator of the client.
+type DynamoDBCheckpointStore struct {
+	c      *Client
+	prefix string
+}
+
+// NewDynamoDBCheckpointStore returns a CheckpointStore writing checkpoints to the table of the client.
+// The table can be the streamed one: each checkpoint then adds a record to the stream, so the consumer doesn't
+// write a checkpoint after a batch holding only the records of its own checkpoints, which would otherwise
+// make an idle table write one checkpoint per poll and shard. A separate table avoids these records altogether.
+//
+// Example:
+//
+//	consumer := db.NewStreamConsumer(
+//		dynamodbstreams.NewFromConfig(cfg),
+//		streamArn,
+//		WithCheckpointStore(NewDynamoDBCheckpointStore(db, "checkpoint#")),
+//	)
+func NewDynamoDBCheckpointStore(c *Client, prefix string) *DynamoDBCheckpointStore {
+	return &DynamoDBCheckpointStore{c: c, prefix: prefix}
+}
+
+// GetCheckpoint reads the checkpoint of the shard.
+func (d *DynamoDBCheckpointStore) GetCheckpoint(ctx context.Context, streamArn, shardID string) (string, error) {
+	out, err := d.c.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(d.c.tableName),
+		Key:            d.key(streamArn, shardID),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return "", d.c.opError(opGetCheckpoint, d.key(streamArn, shardID), err)
+	}
+	if v, ok := out.Item[checkpointAttribute].(*types.AttributeValueMemberS); ok {
+		return v.Value, nil
+	}
+	return "", nil
+}
+
+// SetCheckpoint writes the checkpoint of the shard.
+func (d *DynamoDBCheckpointStore) SetCheckpoint(ctx context.Context, streamArn, shardID, sequenceNumber string) error {
+	item := d.key(streamArn, shardID)
+	item[checkpointAttribute] = &types.AttributeValueMemberS{Value: sequenceNumber}
+	_, err := d.c.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(d.c.tableName),
+		Item:      item,
+	})
+	if err != nil {
+		return d.c.opError(opSetCheckpoint, d.key(streamArn, shardID), err)
+	}
+	return nil
+}
+
+// key returns the primary key of the checkpoint item of the shard.
+func (d *DynamoDBCheckpointStore) key(streamArn, shardID string) map[string]types.AttributeValue {
+	if d.c.sortKey == "" {
+		return map[string]types.AttributeValue{
+			d.c.partitionKey: &types.AttributeValueMemberS{Value: d.prefix + streamArn + d.c.keySeparator + shardID},
+		}
+	}
+	return map[string]types.AttributeValue{
+		d.c.partitionKey: &types.AttributeValueMemberS{Value: d.prefix + streamArn},
+		d.c.sortKey:      &types.AttributeValueMemberS{Value: shardID},
+	}
+}
+
+// isCheckpointItem reports whether the key is the one of a checkpoint of the stream.
+func (d *DynamoDBCheckpointStore) isCheckpointItem(streamArn string, key map[string]types.AttributeValue) bool {
+	pk, ok := key[d.c.partitionKey].(*types.AttributeValueMemberS)
+	return ok && strings.HasPrefix(pk.Value, d.prefix+streamArn)
+}
@@ -4,11 +4,14 @@ package dygo
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/aws/retry"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/aws/smithy-go/logging"
@@ -30,6 +33,25 @@ type Client struct {
 	maxRetry     int
 	logger       *log.Logger
 	keySeparator string
+	keyProvider  KeyProvider
+
+	attributeTypes map[string]AttributeType
+
+	blindIndexKey []byte
+	blindIndexes  map[string]string
+
+	compression          Codec
+	compressionThreshold int
+	codecs               map[string]Codec
+
+	blobStore        BlobStore
+	offloadThreshold int
+
+	cache      Cache
+	cacheFills *cacheFills
+
+	operationTimeouts map[string]time.Duration
+	writeRate         *rateController
 }
 
 // GSI is a struct that represents a Global Secondary Index (GSI) for the client.
@@ -43,7 +65,7 @@ type gsi struct {
 // It takes a profile string as a parameter and returns an error.
 func WithProfile(profile string) Option {
 	return func(c *Client) error {
-		c.tableName = profile
+		c.profile = profile
 		return nil
 	}
 }
@@ -103,6 +125,28 @@ func WithGSI(indexName, partitionKey, sortKey string) Option {
 	}
 }
 
+// WithAttributeType is an optional option function that declares the type of a key attribute
+// of the table or of a GSI: AttributeTypeString, AttributeTypeNumber or AttributeTypeBinary.
+// Key values given for a declared attribute, in PK, SK, GSI and LastEvaluatedKey, are checked against its type.
+// Undeclared key attributes accept values of any of these types.
+// Example:
+//
+//	client := NewClient(WithPartitionKey("tenant_id"), WithAttributeType("tenant_id", AttributeTypeNumber))
+func WithAttributeType(attributeName string, attributeType AttributeType) Option {
+	return func(c *Client) error {
+		switch attributeType {
+		case AttributeTypeString, AttributeTypeNumber, AttributeTypeBinary:
+		default:
+			return fmt.Errorf("invalid key attribute type %q for %s, expected S, N or B", attributeType, attributeName)
+		}
+		if c.attributeTypes == nil {
+			c.attributeTypes = make(map[string]AttributeType)
+		}
+		c.attributeTypes[attributeName] = attributeType
+		return nil
+	}
+}
+
 // WithRegion is a mandatory option function that sets the region for the client.
 // It takes a string parameter representing the region and returns an error.
 // The region is used to configure the client for a specific geographic region.
@@ -143,6 +187,216 @@ func WithRetry(count int) Option {
 	}
 }
 
+// WithKeyProvider is an optional option function that enables client-side field-level encryption.
+// Fields tagged with `dygo:"encrypt"` are encrypted with keys from the provider before they are written,
+// and decrypted transparently on every read.
+//
+// Example:
+//
+//	type user struct {
+//		PK    string `dynamodbav:"_partition_key"`
+//		Email string `dynamodbav:"email" dygo:"encrypt"`
+//	}
+func WithKeyProvider(kp KeyProvider) Option {
+	return func(c *Client) error {
+		c.keyProvider = kp
+		return nil
+	}
+}
+
+// WithBlindIndexKey is an optional option function that sets the HMAC key used to compute blind indexes.
+// Attributes registered with WithBlindIndex store a deterministic HMAC of their plaintext value in their index attribute,
+// which allows encrypted attributes to be looked up without storing them in clear text.
+// The key must stay the same for the lifetime of the data, changing it invalidates all stored blind indexes.
+func WithBlindIndexKey(key []byte) Option {
+	return func(c *Client) error {
+		if len(key) == 0 {
+			return errors.New("blind index key can't be empty")
+		}
+		c.blindIndexKey = key
+		return nil
+	}
+}
+
+// WithBlindIndex is an optional option function that registers indexAttributeName as the blind index of attributeName.
+// It is the only place blind indexes are configured: the items written with Item store the blind index of attributeName
+// in indexAttributeName, KeyEqual and BlindEqual filters on attributeName match the blind index, and GSI lookups on an index
+// whose partition key is indexAttributeName accept the plaintext value. It requires WithBlindIndexKey.
+//
+// Example:
+//
+//	type user struct {
+//		PK    string `dynamodbav:"_partition_key"`
+//		Email string `dynamodbav:"email" dygo:"encrypt"`
+//	}
+//
+//	db, err := NewClient(
+//		...
+//		WithBlindIndexKey(key),
+//		WithBlindIndex("email", "email_bidx"),
+//		WithGSI("email-index", "email_bidx", ""),
+//	)
+//
+//	err = db.
+//		GSI("email-index", "someone@example.com", nil).
+//		Query(context.Background()).
+//		Unmarshal(&data, []string{"user"}).
+//		Run()
+func WithBlindIndex(attributeName, indexAttributeName string) Option {
+	return func(c *Client) error {
+		if c.blindIndexes == nil {
+			c.blindIndexes = make(map[string]string)
+		}
+		if _, ok := c.blindIndexes[attributeName]; ok {
+			return errors.New("duplicate blind index attribute name")
+		}
+		c.blindIndexes[attributeName] = indexAttributeName
+		return nil
+	}
+}
+
+// WithCompression is an optional option function that configures how fields tagged with `dygo:"compress"` are compressed.
+// Values smaller than threshold bytes are stored uncompressed, a threshold <= 0 selects the default of 1024 bytes.
+// Compressed values are stored as Binary attributes and decompressed transparently on every read.
+// Without this option tagged fields are compressed with GzipCodec.
+//
+// Example:
+//
+//	type document struct {
+//		PK   string         `dynamodbav:"_partition_key"`
+//		Body map[string]any `dynamodbav:"body" dygo:"compress"`
+//	}
+//
+//	db, err := NewClient(
+//		...
+//		WithCompression(GzipCodec{Level: gzip.BestCompression}, 4096),
+//	)
+func WithCompression(codec Codec, threshold int) Option {
+	return func(c *Client) error {
+		if codec == nil {
+			return errors.New("compression codec can't be nil")
+		}
+		c.compression = codec
+		c.compressionThreshold = threshold
+		return WithCodec(codec)(c)
+	}
+}
+
+// WithCodec is an optional option function that registers an additional codec used to decompress values.
+// It is needed only to read values written with a codec other than the one set by WithCompression.
+func WithCodec(codec Codec) Option {
+	return func(c *Client) error {
+		if codec == nil {
+			return errors.New("codec can't be nil")
+		}
+		if c.codecs == nil {
+			c.codecs = make(map[string]Codec)
+		}
+		c.codecs[codec.Name()] = codec
+		return nil
+	}
+}
+
+// WithBlobStore is an optional option function that enables offloading of large attributes to a blob store.
+// When an item is larger than threshold bytes after compression, fields tagged with `dygo:"offload"` are written
+// to the store and replaced by a pointer in the item. Offloaded attributes are rehydrated transparently on every read,
+// and their blobs are removed when the item is deleted or overwritten.
+// A threshold <= 0 offloads only items exceeding the 400KB DynamoDB item size limit.
+//
+// Example:
+//
+//	type document struct {
+//		PK   string         `dynamodbav:"_partition_key"`
+//		Body map[string]any `dynamodbav:"body" dygo:"compress,offload"`
+//	}
+//
+//	store, err := NewFileBlobStore("/tmp/blobs")
+//	db, err := NewClient(
+//		...
+//		WithBlobStore(store, 0),
+//	)
+func WithBlobStore(store BlobStore, threshold int) Option {
+	return func(c *Client) error {
+		if store == nil {
+			return errors.New("blob store can't be nil")
+		}
+		c.blobStore = store
+		c.offloadThreshold = threshold
+		return nil
+	}
+}
+
+// WithCache is an optional option function that enables a read-through item cache.
+// GetItem, GetAuthorizedItem and BatchGetItem serve items from the cache, while Create, Upsert, Update, Delete
+// and the batch writers invalidate the items they write. Authorization still runs for items served from the cache.
+// Use BypassCache to read a single request directly from DynamoDB.
+//
+// Example:
+//
+//	db, err := NewClient(
+//		...
+//		WithCache(NewLRUCache(10000, time.Minute)),
+//	)
+func WithCache(cache Cache) Option {
+	return func(c *Client) error {
+		c.cache = cache
+		c.cacheFills = newCacheFills()
+		return nil
+	}
+}
+
+// WithOperationTimeout is an optional option function that limits the duration of every request
+// of the DynamoDB API operation op, such as GetItem, Query or BatchWriteItem, retries included.
+// The DynamoDB Streams operations DescribeStream, GetShardIterator and GetRecords of a StreamConsumer are limited as well.
+// It returns an error if op isn't an operation called by the package.
+// The deadline of the context passed to the operation still applies when it is earlier.
+//
+// Example:
+//
+//	db, err := NewClient(
+//		...
+//		WithOperationTimeout("GetItem", 2*time.Second),
+//		WithOperationTimeout("BatchWriteItem", 10*time.Second),
+//	)
+func WithOperationTimeout(op string, d time.Duration) Option {
+	return func(c *Client) error {
+		if !timeoutOperations[op] {
+			return fmt.Errorf("unknown operation %q", op)
+		}
+		if d <= 0 {
+			return fmt.Errorf("timeout of %s must be positive", op)
+		}
+		if c.operationTimeouts == nil {
+			c.operationTimeouts = make(map[string]time.Duration)
+		}
+		c.operationTimeouts[op] = d
+		return nil
+	}
+}
+
+// WithWriteRate is an optional option function that limits the batch writes of the client to a target rate,
+// in written items per second, shared by all the batch operations of the client.
+// The rate is halved when DynamoDB throttles the writes or leaves items unprocessed,
+// and grows back towards the target as writes succeed.
+// Without it, the batch writes and Update of the client aren't rate limited: they send requests as fast as their
+// threads allow, and throttled requests are only retried with an exponential backoff.
+//
+// Example:
+//
+//	db, err := NewClient(
+//		...
+//		WithWriteRate(500),
+//	)
+func WithWriteRate(itemsPerSecond float64) Option {
+	return func(c *Client) error {
+		if itemsPerSecond <= 0 {
+			return errors.New("write rate must be positive")
+		}
+		c.writeRate = newRateController(itemsPerSecond)
+		return nil
+	}
+}
+
 // Define a custom logger that satisfies the log.Logger interface.
 type customLogger struct {
 	logger *log.Logger
@@ -221,7 +475,11 @@ func NewClient(opts ...Option) (*Client, error) {
 	if err != nil {
 		return nil, err
 	}
-	c.client = dynamodb.NewFromConfig(cfg)
+	c.client = dynamodb.NewFromConfig(cfg, func(o *dynamodb.Options) {
+		if len(c.operationTimeouts) > 0 {
+			o.APIOptions = append(o.APIOptions, c.addOperationTimeout)
+		}
+	})
 	e := c.validate()
 	if e != nil {
 		return nil, e
@@ -232,7 +490,7 @@ func NewClient(opts ...Option) (*Client, error) {
 // validate checks if the required fields of the Client struct are set.
 // If any required field is missing, it returns an Error with the corresponding error message.
 // If all required fields are set, it returns nil.
-func (c *Client) validate() *dError {
+func (c *Client) validate() *OpError {
 	var msg string
 	switch {
 	case c.partitionKey == "":
@@ -241,9 +499,11 @@ func (c *Client) validate() *dError {
 		msg = errMissingRegion
 	case c.client == nil:
 		msg = errMissingClient
+	case len(c.blindIndexes) > 0 && len(c.blindIndexKey) == 0:
+		msg = errMissingBlindIndexKey
 	}
 	if msg != "" {
-		return dynamoError().method("NewClient").message(msg)
+		return validationError().method("NewClient").message(msg)
 	}
 	return nil
 }
@@ -401,6 +661,20 @@ func (c *Client) UpdateItemRaw(item map[string]types.AttributeValue) *Item {
 	return i
 }
 
+// BypassCache makes the read skip the item cache configured with WithCache, both for lookups and for refills.
+//
+// Example:
+//
+//	 err = db.
+//		PK("pk").
+//		SK(dygo.Equal("sk")).
+//		BypassCache().
+//		GetItem(context.Background(), &data)
+func (i *Item) BypassCache() *Item {
+	i.bypassCache = true
+	return i
+}
+
 // Project sets the projection for the item.
 // It takes a variadic parameter `value` which represents the projection fields.
 //
@@ -417,6 +691,7 @@ func (i *Item) Project(value ...string) *Item {
 
 // Filter applies a filter function to the specified attribute of the item.
 // Possible values for FilterFunc are KeyEqual, KeyNotEqual, KeyBeginsWith, KeyBetween, KeyLessThan, KeyLessThanEqual, KeyGreaterThan, KeyGreaterThanEqual, KeyContains, KeyNotNull, KeyNull, KeyIn.
+// They can be grouped with And, Or and Not, and bound to other attributes with On.
 //
 // Example:
 //
@@ -430,9 +705,10 @@ func (i *Item) Filter(attributeName string, filterFunc FilterFunc) *Item {
 }
 
 // Condition sets condition for ConditionExpression.
-// NOTE: It is currently supported for only Upsert operation.
+// NOTE: It is currently supported for only Upsert operation, and for the items added to Update with AddUpdateItem and AddUpdateRawItem.
 // It takes the attribute name and condition function as parameters.
 // Possible values for ConditionFunc are ConditionEqual, ConditionNotEqual, ConditionLessThan, ConditionLessThanEqual, ConditionGreaterThan, ConditionGreaterThanEqual, ConditionBetween, ConditionIn, ConditionAttributeExists, ConditionAttributeNotExists and ConditionBeginsWith.
+// They can be grouped with And, Or and Not, and bound to other attributes with On.
 // Example:
 //
 //	 err = db.
@@ -500,12 +776,7 @@ func (i *Item) AddBatchGetItem(newItem *Item, omitEmptyKeys bool) {
 // isPartitionKeyEmpty checks if the partition key of the item is empty.
 func (i *Item) isPartitionKeyEmpty() bool {
 	if pk, ok := i.key[i.c.partitionKey]; ok {
-		switch v := pk.(type) {
-		case *types.AttributeValueMemberS:
-			if v.Value == "" {
-				return true
-			}
-		}
+		return isEmptyKeyValue(pk)
 	}
 	return false
 }
@@ -529,6 +800,7 @@ func (i *Item) AddBatchDeleteItem(newItem *Item) {
 }
 
 // AddBatchUpsertItem adds a new item to the batch upsert operation.
+// The item is encrypted, compressed and offloaded according to its dygo struct tags when the batch runs.
 //
 // Example:
 //
@@ -540,7 +812,7 @@ func (i *Item) AddBatchDeleteItem(newItem *Item) {
 func (i *Item) AddBatchUpsertItem(newItem *Item) {
 	err := i.item.Validate()
 	if err != nil {
-		newItem.err = dynamoError().method("opValidate").message(err.Error())
+		newItem.err = validationError().method("opValidate").wrap(err)
 		return
 	}
 	i.fillItem(newItem)
@@ -548,6 +820,7 @@ func (i *Item) AddBatchUpsertItem(newItem *Item) {
 }
 
 // AddUpdateRawItem adds a new raw item (types.AttributeValue) to the update operation.
+// The condition set with Condition, if any, applies to the update of the item.
 //
 // Example:
 //
@@ -558,9 +831,44 @@ func (i *Item) AddBatchUpsertItem(newItem *Item) {
 //
 //	err = newItem.Update(context.Background(), 5)
 func (i *Item) AddUpdateRawItem(newItem *Item) {
+	for n := range i.batchData.updateItems {
+		i.batchData.updateItems[n].condition = i.condition
+	}
 	i.fillItem(newItem)
 }
 
+// AddUpdateItem adds the update of the item with the key set on i to the update operation,
+// applying the update expression. The condition set with Condition, if any, applies to the update of the item.
+//
+// Example:
+//
+//	newItem := new(Item)
+//	for _, id := range ids {
+//		db.PK(id).SK(Equal(SK)).
+//			Condition("version", ConditionEqual(1)).
+//			AddUpdateItem(newItem, expression.Set(expression.Name("version"), expression.Value(2)))
+//	}
+//
+//	err = newItem.Update(context.Background(), 5)
+func (i *Item) AddUpdateItem(newItem *Item, update expression.UpdateBuilder) {
+	if newItem.c == nil {
+		newItem.c = i.c
+	}
+	if i.err != nil {
+		if newItem.err == nil {
+			newItem.err = i.err
+		}
+		return
+	}
+	if i.c != newItem.c {
+		if newItem.err == nil {
+			newItem.err = validationError().method(opUpdate).message("item doesn't belong to the client of the update")
+		}
+		return
+	}
+	newItem.batchData.updateItems = append(newItem.batchData.updateItems, updateItem{key: i.key, update: update, condition: i.condition})
+}
+
 // AddBatchUpsertRawItem adds a new raw item (types.AttributeValue) to the batch upsert operation.
 //
 // Example:
@@ -0,0 +1,538 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/QuollioLabs/dygo"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// keyFlags select an item or a partition by its key.
+type keyFlags struct {
+	pk       string
+	typ      string
+	id       string
+	sk       string
+	skOp     string
+	skEnd    string
+	index    string
+	entity   string
+	filters  multiFlag
+	project  string
+	limit    int
+	startKey string
+	desc     bool
+	output   string
+}
+
+func newKeyFlags(fs *flag.FlagSet, query bool) *keyFlags {
+	f := &keyFlags{}
+	fs.StringVar(&f.pk, "pk", "", "partition key value")
+	fs.StringVar(&f.typ, "type", "", "entity type, combined with -id into the partition key value")
+	fs.StringVar(&f.id, "id", "", "entity ID, combined with -type into the partition key value")
+	fs.StringVar(&f.sk, "sk", "", "sort key value")
+	f.outputFlags(fs)
+	if query {
+		fs.StringVar(&f.skOp, "sk-op", "eq", "sort key operator: eq, lt, le, gt, ge, begins_with or between")
+		fs.StringVar(&f.skEnd, "sk-end", "", "upper bound of the sort key for -sk-op between")
+		fs.StringVar(&f.index, "index", "", "GSI to query")
+		fs.StringVar(&f.entity, "entity", "", "entity type to query on the first GSI, or on -index")
+		f.pageFlags(fs)
+		fs.BoolVar(&f.desc, "desc", false, "return items in descending sort key order")
+	}
+	return f
+}
+
+// newScanFlags registers the flags of a scan, which reads the whole table and has no key.
+func newScanFlags(fs *flag.FlagSet) *keyFlags {
+	f := &keyFlags{}
+	f.outputFlags(fs)
+	f.pageFlags(fs)
+	return f
+}
+
+func (f *keyFlags) outputFlags(fs *flag.FlagSet) {
+	fs.StringVar(&f.output, "output", "json", "output format: json or table")
+	fs.StringVar(&f.project, "project", "", "comma separated attributes to return")
+}
+
+func (f *keyFlags) pageFlags(fs *flag.FlagSet) {
+	fs.Var(&f.filters, "filter", `filter as "attribute operator value", repeatable and combined with AND`)
+	fs.IntVar(&f.limit, "limit", 0, "maximum number of items of a page, 0 reads all pages")
+	fs.StringVar(&f.startKey, "start-key", "", "JSON key to continue a paginated read from")
+}
+
+// partitionValue returns the partition key value given by -pk, or by -type and -id joined with the key separator.
+func (f *keyFlags) partitionValue(cfg config) (string, error) {
+	switch {
+	case f.pk != "":
+		return f.pk, nil
+	case f.typ != "" && f.id != "":
+		return f.typ + cfg.KeySeparator + f.id, nil
+	}
+	return "", errors.New("partition key is missing, set -pk or -type and -id")
+}
+
+// sortKey returns the sort key condition given by -sk and -sk-op, or nil if there is none.
+func (f *keyFlags) sortKey() (dygo.SortKeyFunc, error) {
+	if f.sk == "" {
+		return nil, nil
+	}
+	switch f.skOp {
+	case "", "eq", "=":
+		return dygo.Equal(f.sk), nil
+	case "lt", "<":
+		return dygo.LessThan(f.sk), nil
+	case "le", "<=":
+		return dygo.LessThanOrEqual(f.sk), nil
+	case "gt", ">":
+		return dygo.GreaterThan(f.sk), nil
+	case "ge", ">=":
+		return dygo.GreaterThanOrEqual(f.sk), nil
+	case "begins_with":
+		return dygo.BeginsWith(f.sk), nil
+	case "between":
+		if f.skEnd == "" {
+			return nil, errors.New("-sk-op between requires -sk-end")
+		}
+		return dygo.Between(f.sk, f.skEnd), nil
+	}
+	return nil, fmt.Errorf("unknown sort key operator %q", f.skOp)
+}
+
+// item returns the item addressed by the key flags, applying filters, projection and pagination.
+func (f *keyFlags) item(c *dygo.Client, cfg config) (*dygo.Item, error) {
+	sk, err := f.sortKey()
+	if err != nil {
+		return nil, err
+	}
+
+	var item *dygo.Item
+	if f.index != "" || f.entity != "" {
+		index := f.index
+		if index == "" {
+			if len(cfg.GSIs) == 0 {
+				return nil, errors.New("-entity requires a GSI, set -gsi or gsis in the config file")
+			}
+			index = cfg.GSIs[0].Name
+		}
+		value := f.entity
+		if value == "" {
+			if value, err = f.partitionValue(cfg); err != nil {
+				return nil, err
+			}
+		}
+		item = c.GSI(index, value, sk)
+	} else {
+		pk, err := f.partitionValue(cfg)
+		if err != nil {
+			return nil, err
+		}
+		item = c.PK(pk)
+		if sk != nil {
+			item = item.SK(sk)
+		}
+	}
+	return f.apply(item)
+}
+
+// apply sets the filters, projection and pagination of the flags on item.
+func (f *keyFlags) apply(item *dygo.Item) (*dygo.Item, error) {
+	for n, spec := range f.filters {
+		attr, filter, err := parseFilterFlag(spec)
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			item = item.Filter(attr, filter)
+		} else {
+			item = item.AndFilter(attr, filter)
+		}
+	}
+	if f.project != "" {
+		item = item.Project(strings.Split(f.project, ",")...)
+	}
+	if f.limit > 0 {
+		item = item.Limit(f.limit)
+	}
+	if f.startKey != "" {
+		key, err := parseStartKey(f.startKey)
+		if err != nil {
+			return nil, err
+		}
+		item = item.LastEvaluatedKey(key)
+	}
+	if f.desc {
+		// ScanIndexForward(true) makes the query read in descending order
+		item = item.ScanIndexForward(true)
+	}
+	return item, nil
+}
+
+// parseFilterFlag parses a filter given as "attribute operator value".
+func parseFilterFlag(spec string) (string, dygo.FilterFunc, error) {
+	parts := strings.SplitN(strings.TrimSpace(spec), " ", 3)
+	if len(parts) < 2 {
+		return "", nil, fmt.Errorf("invalid filter %q, expected \"attribute operator value\"", spec)
+	}
+	attr, op := parts[0], parts[1]
+	switch op {
+	case "exists":
+		return attr, dygo.KeyNotNull(), nil
+	case "not_exists":
+		return attr, dygo.KeyNull(), nil
+	}
+	if len(parts) != 3 {
+		return "", nil, fmt.Errorf("filter %q is missing a value", spec)
+	}
+	value := parts[2]
+	switch op {
+	case "=", "eq":
+		return attr, dygo.KeyEqual(value), nil
+	case "<>", "ne":
+		return attr, dygo.KeyNotEqual(value), nil
+	case "<", "lt":
+		return attr, dygo.KeyLessThan(value), nil
+	case "<=", "le":
+		return attr, dygo.KeyLessThanOrEqual(value), nil
+	case ">", "gt":
+		return attr, dygo.KeyGreaterThan(value), nil
+	case ">=", "ge":
+		return attr, dygo.KeyGreaterThanOrEqual(value), nil
+	case "begins_with":
+		return attr, dygo.KeyBeginsWith(value), nil
+	case "contains":
+		return attr, dygo.KeyContains(value), nil
+	}
+	return "", nil, fmt.Errorf("unknown filter operator %q", op)
+}
+
+// parseStartKey parses a JSON key as printed after a paginated read.
+func parseStartKey(data string) (map[string]any, error) {
+	dec := json.NewDecoder(strings.NewReader(data))
+	dec.UseNumber()
+	var key map[string]any
+	if err := dec.Decode(&key); err != nil {
+		return nil, fmt.Errorf("invalid -start-key: %w", err)
+	}
+	for name, v := range key {
+		if n, ok := v.(json.Number); ok {
+			i, err := n.Int64()
+			if err != nil {
+				return nil, fmt.Errorf("invalid -start-key: %s is not an integer", name)
+			}
+			key[name] = int(i)
+		}
+	}
+	return key, nil
+}
+
+func runGet(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("get", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	cf := newClientFlags(fs)
+	kf := newKeyFlags(fs, false)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	c, cfg, err := cf.client()
+	if err != nil {
+		return err
+	}
+	item, err := kf.item(c, cfg)
+	if err != nil {
+		return err
+	}
+
+	var out rawItem
+	if err := item.GetItem(ctx, &out); err != nil {
+		return err
+	}
+	if len(out) == 0 {
+		return errors.New("item not found")
+	}
+	return writeJSONItems(stdout, kf.output, []map[string]any{plainItem(out)}, cfg)
+}
+
+// rawItem receives an item without converting its attribute values, so numbers are printed exactly.
+type rawItem map[string]types.AttributeValue
+
+// UnmarshalDynamoDBAttributeValue implements attributevalue.Unmarshaler.
+func (r *rawItem) UnmarshalDynamoDBAttributeValue(av types.AttributeValue) error {
+	if _, ok := av.(*types.AttributeValueMemberNULL); ok {
+		return nil
+	}
+	m, ok := av.(*types.AttributeValueMemberM)
+	if !ok {
+		return fmt.Errorf("expected an item, got %T", av)
+	}
+	*r = m.Value
+	return nil
+}
+
+func runQuery(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("query", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	cf := newClientFlags(fs)
+	kf := newKeyFlags(fs, true)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	c, cfg, err := cf.client()
+	if err != nil {
+		return err
+	}
+	item, err := kf.item(c, cfg)
+	if err != nil {
+		return err
+	}
+
+	out := item.Query(ctx)
+	if err := out.Run(); err != nil {
+		return err
+	}
+	return writeResults(stdout, stderr, kf.output, out.Results, out.LastEvaluatedKey, cfg)
+}
+
+func runScan(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("scan", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	cf := newClientFlags(fs)
+	kf := newScanFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	c, cfg, err := cf.client()
+	if err != nil {
+		return err
+	}
+	item, err := kf.apply(c.InitScan())
+	if err != nil {
+		return err
+	}
+
+	out := item.Scan(ctx)
+	if err := out.Run(); err != nil {
+		return err
+	}
+	return writeResults(stdout, stderr, kf.output, out.Results, out.LastEvaluatedKey, cfg)
+}
+
+func runCount(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("count", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	cf := newClientFlags(fs)
+	kf := newKeyFlags(fs, true)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	c, cfg, err := cf.client()
+	if err != nil {
+		return err
+	}
+	item, err := kf.item(c, cfg)
+	if err != nil {
+		return err
+	}
+
+	total, filtered, err := item.Count(ctx)
+	if err != nil {
+		return err
+	}
+	if kf.output == "table" {
+		fmt.Fprintf(stdout, "total\tfiltered\n%d\t%d\n", total, filtered)
+		return nil
+	}
+	return json.NewEncoder(stdout).Encode(map[string]int{"total": total, "filtered": filtered})
+}
+
+// jsonItem is an item given as JSON on the command line. It is written as is, without validation.
+type jsonItem map[string]any
+
+func (jsonItem) Validate() error {
+	return nil
+}
+
+func runPut(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("put", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	cf := newClientFlags(fs)
+	file := fs.String("file", "", "file with the JSON item, read from stdin if empty and no item argument is given")
+	create := fs.Bool("create", false, "fail if the item already exists instead of replacing it")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	c, _, err := cf.client()
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	switch {
+	case fs.NArg() > 0:
+		data = []byte(strings.Join(fs.Args(), " "))
+	case *file != "":
+		data, err = os.ReadFile(*file)
+	default:
+		data, err = io.ReadAll(stdin)
+	}
+	if err != nil {
+		return err
+	}
+	item, err := parseJSONItem(data)
+	if err != nil {
+		return err
+	}
+
+	if *create {
+		return c.Item(item).Create(ctx)
+	}
+	return c.Item(item).Upsert(ctx)
+}
+
+// parseJSONItem parses a plain JSON object, keeping numbers exact.
+func parseJSONItem(data []byte) (jsonItem, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var raw map[string]any
+	if err := dec.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("invalid item: %w", err)
+	}
+	item := make(jsonItem, len(raw))
+	for name, v := range raw {
+		item[name] = jsonNumbers(v)
+	}
+	return item, nil
+}
+
+// jsonNumbers replaces the json.Number values of v, which attributevalue would store as strings, with attributevalue.Number.
+func jsonNumbers(v any) any {
+	switch v := v.(type) {
+	case json.Number:
+		return attributevalue.Number(v)
+	case []any:
+		for n, elem := range v {
+			v[n] = jsonNumbers(elem)
+		}
+	case map[string]any:
+		for name, elem := range v {
+			v[name] = jsonNumbers(elem)
+		}
+	}
+	return v
+}
+
+func runDelete(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("delete", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	cf := newClientFlags(fs)
+	kf := newKeyFlags(fs, false)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	c, cfg, err := cf.client()
+	if err != nil {
+		return err
+	}
+	item, err := kf.item(c, cfg)
+	if err != nil {
+		return err
+	}
+	return item.Delete(ctx)
+}
+
+func runExport(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	cf := newClientFlags(fs)
+	file := fs.String("file", "", "file to write, stdout if empty")
+	format := fs.String("format", string(dygo.FormatDynamoDBJSON), "line format: dynamodb-json or json")
+	segments := fs.Int("segments", 1, "number of parallel scan segments")
+	decode := fs.Bool("decode", false, "write encrypted, compressed and offloaded attributes decoded")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	c, _, err := cf.client()
+	if err != nil {
+		return err
+	}
+
+	w := stdout
+	if *file != "" {
+		f, err := os.Create(*file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	n, err := c.Export(ctx, w, dygo.ExportOptions{
+		Format:   dygo.Format(*format),
+		Segments: *segments,
+		Decode:   *decode,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stderr, "exported %d items\n", n)
+	return nil
+}
+
+func runImport(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	cf := newClientFlags(fs)
+	file := fs.String("file", "", "file to read, stdin if empty")
+	format := fs.String("format", string(dygo.FormatDynamoDBJSON), "line format: dynamodb-json or json")
+	conflict := fs.String("conflict", "overwrite", "policy for existing items: overwrite, skip or fail")
+	offset := fs.Int("offset", 0, "number of lines to skip, to resume a failed import")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	c, _, err := cf.client()
+	if err != nil {
+		return err
+	}
+
+	policies := map[string]dygo.ConflictPolicy{
+		"overwrite": dygo.ConflictOverwrite,
+		"skip":      dygo.ConflictSkip,
+		"fail":      dygo.ConflictFail,
+	}
+	policy, ok := policies[*conflict]
+	if !ok {
+		return fmt.Errorf("unknown conflict policy %q", *conflict)
+	}
+
+	r := stdin
+	if *file != "" {
+		f, err := os.Open(*file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	result, err := c.Import(ctx, r, dygo.ImportOptions{
+		Format:   dygo.Format(*format),
+		Conflict: policy,
+		Offset:   *offset,
+		Progress: func(r dygo.ImportResult) {
+			fmt.Fprintf(stderr, "imported %d lines\n", r.Lines)
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("%w (resume with -offset %d)", err, result.Lines)
+	}
+	fmt.Fprintf(stderr, "imported %d lines: %d written, %d skipped\n", result.Lines, result.Written, result.Skipped)
+	return nil
+}
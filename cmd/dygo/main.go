@@ -0,0 +1,223 @@
+// Command dygo reads and writes items of a single-table DynamoDB design from the command line,
+// using the same client options, key separator and entity-type conventions as the dygo package.
+//
+// Usage:
+//
+//	dygo <command> [flags]
+//
+// The commands are get, query, scan, put, delete, count, export and import.
+// Client options are read from a JSON config file given with -config or the DYGO_CONFIG environment variable,
+// and can be overridden with flags:
+//
+//	{
+//		"table": "test-table-1",
+//		"region": "ap-northeast-1",
+//		"profile": "default",
+//		"endpoint": "http://localhost:8000",
+//		"partition_key": "_partition_key",
+//		"sort_key": "_sort_key",
+//		"key_separator": "#",
+//		"gsis": [{"name": "gsi-name", "partition_key": "_entity_type", "sort_key": "_sort_key"}]
+//	}
+//
+// Examples:
+//
+//	dygo get -type room -id 1 -sk current
+//	dygo query -entity room -sk-op begins_with -sk 2023 -filter "physical_name begins_with lib" -output table
+//	dygo scan -limit 20 -start-key '{"_partition_key":"room#1","_sort_key":"current"}'
+//	echo '{"_partition_key":"room#1","_sort_key":"current","name":"Main hall"}' | dygo put
+//	dygo export -file backup.jsonl -segments 4
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/QuollioLabs/dygo"
+)
+
+// config holds the client options, read from the config file and overridden by flags.
+type config struct {
+	Table        string      `json:"table"`
+	Region       string      `json:"region"`
+	Profile      string      `json:"profile"`
+	Endpoint     string      `json:"endpoint"`
+	PartitionKey string      `json:"partition_key"`
+	SortKey      string      `json:"sort_key"`
+	KeySeparator string      `json:"key_separator"`
+	GSIs         []gsiConfig `json:"gsis"`
+}
+
+type gsiConfig struct {
+	Name         string `json:"name"`
+	PartitionKey string `json:"partition_key"`
+	SortKey      string `json:"sort_key"`
+}
+
+// command is a subcommand of the tool.
+type command struct {
+	name  string
+	usage string
+	run   func(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error
+}
+
+var commands = []command{
+	{"get", "get an item by its key", runGet},
+	{"query", "query a partition of the table or of a GSI", runQuery},
+	{"scan", "scan the table", runScan},
+	{"put", "write an item given as JSON", runPut},
+	{"delete", "delete an item by its key", runDelete},
+	{"count", "count the items of a query", runCount},
+	{"export", "export the table as JSON lines", runExport},
+	{"import", "import JSON lines into the table", runImport},
+}
+
+func main() {
+	if err := run(context.Background(), os.Args[1:], os.Stdin, os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, "dygo:", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		printUsage(stderr)
+		return nil
+	}
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return cmd.run(ctx, args[1:], stdin, stdout, stderr)
+		}
+	}
+	printUsage(stderr)
+	return fmt.Errorf("unknown command %q", args[0])
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: dygo <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-8s %s\n", cmd.name, cmd.usage)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `Run "dygo <command> -h" for the flags of a command.`)
+}
+
+// clientFlags registers the client option flags on fs.
+type clientFlags struct {
+	configPath string
+	override   config
+	gsis       multiFlag
+}
+
+func newClientFlags(fs *flag.FlagSet) *clientFlags {
+	f := &clientFlags{}
+	fs.StringVar(&f.configPath, "config", os.Getenv("DYGO_CONFIG"), "path of the JSON config file")
+	fs.StringVar(&f.override.Table, "table", "", "table name")
+	fs.StringVar(&f.override.Region, "region", "", "AWS region")
+	fs.StringVar(&f.override.Profile, "profile", "", "AWS shared config profile")
+	fs.StringVar(&f.override.Endpoint, "endpoint", "", "DynamoDB endpoint, e.g. http://localhost:8000")
+	fs.StringVar(&f.override.PartitionKey, "partition-key", "", "partition key attribute of the table")
+	fs.StringVar(&f.override.SortKey, "sort-key", "", "sort key attribute of the table")
+	fs.StringVar(&f.override.KeySeparator, "key-separator", "", "separator between the entity type and the ID in keys")
+	fs.Var(&f.gsis, "gsi", "GSI as name:partition_key[:sort_key], repeatable")
+	return f
+}
+
+// config returns the config file merged with the flags.
+func (f *clientFlags) config() (config, error) {
+	var cfg config
+	if f.configPath != "" {
+		data, err := os.ReadFile(f.configPath)
+		if err != nil {
+			return cfg, err
+		}
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			return cfg, fmt.Errorf("config %s: %w", f.configPath, err)
+		}
+	}
+	for _, field := range []struct {
+		dst *string
+		src string
+	}{
+		{&cfg.Table, f.override.Table},
+		{&cfg.Region, f.override.Region},
+		{&cfg.Profile, f.override.Profile},
+		{&cfg.Endpoint, f.override.Endpoint},
+		{&cfg.PartitionKey, f.override.PartitionKey},
+		{&cfg.SortKey, f.override.SortKey},
+		{&cfg.KeySeparator, f.override.KeySeparator},
+	} {
+		if field.src != "" {
+			*field.dst = field.src
+		}
+	}
+	if len(f.gsis) > 0 {
+		cfg.GSIs = nil
+		for _, spec := range f.gsis {
+			parts := strings.Split(spec, ":")
+			if len(parts) < 2 || len(parts) > 3 {
+				return cfg, fmt.Errorf("invalid gsi %q, expected name:partition_key[:sort_key]", spec)
+			}
+			g := gsiConfig{Name: parts[0], PartitionKey: parts[1]}
+			if len(parts) == 3 {
+				g.SortKey = parts[2]
+			}
+			cfg.GSIs = append(cfg.GSIs, g)
+		}
+	}
+	if cfg.KeySeparator == "" {
+		cfg.KeySeparator = "#"
+	}
+	return cfg, nil
+}
+
+// client returns a client configured from the config file and flags.
+func (f *clientFlags) client() (*dygo.Client, config, error) {
+	cfg, err := f.config()
+	if err != nil {
+		return nil, cfg, err
+	}
+	if cfg.Table == "" {
+		return nil, cfg, errors.New("table name is missing, set it with -table or in the config file")
+	}
+	opts := []dygo.Option{
+		dygo.WithTableName(cfg.Table),
+		dygo.WithRegion(cfg.Region),
+		dygo.WithPartitionKey(cfg.PartitionKey),
+		dygo.WithKeySeparator(cfg.KeySeparator),
+	}
+	if cfg.Profile != "" {
+		opts = append(opts, dygo.WithProfile(cfg.Profile))
+	}
+	if cfg.Endpoint != "" {
+		opts = append(opts, dygo.WithEndpoint(cfg.Endpoint))
+	}
+	if cfg.SortKey != "" {
+		opts = append(opts, dygo.WithSortKey(cfg.SortKey))
+	}
+	for _, g := range cfg.GSIs {
+		opts = append(opts, dygo.WithGSI(g.Name, g.PartitionKey, g.SortKey))
+	}
+	c, err := dygo.NewClient(opts...)
+	return c, cfg, err
+}
+
+// multiFlag is a repeatable string flag.
+type multiFlag []string
+
+func (m *multiFlag) String() string {
+	return strings.Join(*m, ", ")
+}
+
+func (m *multiFlag) Set(value string) error {
+	*m = append(*m, value)
+	return nil
+}
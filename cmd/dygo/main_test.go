@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_config_file_and_flags(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dygo.json")
+	os.WriteFile(path, []byte(`{
+		"table": "test-table-1",
+		"region": "ap-northeast-1",
+		"partition_key": "_partition_key",
+		"sort_key": "_sort_key",
+		"gsis": [{"name": "gsi-name", "partition_key": "_entity_type", "sort_key": "_sort_key"}]
+	}`), 0o644)
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	cf := newClientFlags(fs)
+	if err := fs.Parse([]string{"-config", path, "-table", "test-table-2"}); err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	cfg, err := cf.config()
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	assert.Equal(t, "test-table-2", cfg.Table)
+	assert.Equal(t, "ap-northeast-1", cfg.Region)
+	assert.Equal(t, "#", cfg.KeySeparator)
+	assert.Equal(t, []gsiConfig{{Name: "gsi-name", PartitionKey: "_entity_type", SortKey: "_sort_key"}}, cfg.GSIs)
+
+	kf := &keyFlags{typ: "room", id: "1"}
+	pk, err := kf.partitionValue(cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, "room#1", pk)
+}
+
+func Test_parse_flags(t *testing.T) {
+	attr, filter, err := parseFilterFlag("physical_name begins_with lib rary")
+	assert.NoError(t, err)
+	assert.Equal(t, "physical_name", attr)
+	assert.NotNil(t, filter)
+
+	_, _, err = parseFilterFlag("physical_name like lib")
+	assert.Error(t, err)
+	_, _, err = parseFilterFlag("physical_name =")
+	assert.Error(t, err)
+	_, _, err = parseFilterFlag("physical_name exists")
+	assert.NoError(t, err)
+
+	key, err := parseStartKey(`{"_partition_key":"room#1","version":3}`)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"_partition_key": "room#1", "version": 3}, key)
+
+	_, err = (&keyFlags{sk: "a", skOp: "between"}).sortKey()
+	assert.Error(t, err)
+
+	item, err := parseJSONItem([]byte(`{"_partition_key":"room#1","count":12345678901234567890,"tags":[1.5]}`))
+	assert.NoError(t, err)
+	av, err := attributevalue.MarshalMap(item)
+	assert.NoError(t, err)
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "12345678901234567890"}, av["count"])
+	assert.Equal(t, &types.AttributeValueMemberL{Value: []types.AttributeValue{&types.AttributeValueMemberN{Value: "1.5"}}}, av["tags"])
+}
+
+func Test_output(t *testing.T) {
+	results := []map[string]types.AttributeValue{
+		{
+			"name":           &types.AttributeValueMemberS{Value: "Main hall"},
+			"_sort_key":      &types.AttributeValueMemberS{Value: "current"},
+			"_partition_key": &types.AttributeValueMemberS{Value: "room#1"},
+			"capacity":       &types.AttributeValueMemberN{Value: "80"},
+		},
+	}
+	cfg := config{PartitionKey: "_partition_key", SortKey: "_sort_key"}
+	var stdout, stderr bytes.Buffer
+	err := writeResults(&stdout, &stderr, "table", results, map[string]types.AttributeValue{
+		"_partition_key": &types.AttributeValueMemberS{Value: "room#1"},
+	}, cfg)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	assert.Equal(t, []string{"_partition_key", "_sort_key", "capacity", "name"}, strings.Fields(lines[0]))
+	assert.Contains(t, stderr.String(), `-start-key '{"_partition_key":"room#1"}'`)
+
+	stdout.Reset()
+	assert.NoError(t, writeResults(&stdout, &stderr, "json", results, nil, cfg))
+	assert.Contains(t, stdout.String(), `"capacity":80`)
+
+	var out rawItem
+	assert.NoError(t, attributevalue.UnmarshalMap(results[0], &out))
+	assert.Equal(t, results[0], map[string]types.AttributeValue(out))
+}
+
+func Test_unknown_command(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	assert.Error(t, run(context.Background(), []string{"truncate"}, nil, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "Commands:")
+	assert.Error(t, run(context.Background(), []string{"get", "-pk", "room#1"}, nil, &stdout, &stderr))
+}
+
+func Test_query_desc(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var input struct{ ScanIndexForward *bool }
+		json.NewDecoder(r.Body).Decode(&input)
+		items := []string{"current_0", "current_1", "current_2"}
+		if input.ScanIndexForward != nil && !*input.ScanIndexForward {
+			items = []string{"current_2", "current_1", "current_0"}
+		}
+		var out []map[string]any
+		for _, sk := range items {
+			out = append(out, map[string]any{
+				"_partition_key": map[string]string{"S": "room#1"},
+				"_sort_key":      map[string]string{"S": sk},
+			})
+		}
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+		json.NewEncoder(w).Encode(map[string]any{"Items": out, "Count": len(out)})
+	}))
+	defer srv.Close()
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+
+	query := func(args ...string) []string {
+		var stdout, stderr bytes.Buffer
+		args = append([]string{"query", "-table", "test-table-1", "-region", "ap-northeast-1", "-endpoint", srv.URL,
+			"-partition-key", "_partition_key", "-sort-key", "_sort_key", "-pk", "room#1", "-sk-op", "begins_with", "-sk", "current"}, args...)
+		if err := run(context.Background(), args, nil, &stdout, &stderr); err != nil {
+			t.Fatalf("unexpected error : %v", err)
+		}
+		var sks []string
+		for _, line := range strings.Split(strings.TrimSpace(stdout.String()), "\n") {
+			var item map[string]any
+			if err := json.Unmarshal([]byte(line), &item); err != nil {
+				t.Fatalf("unexpected error : %v", err)
+			}
+			sks = append(sks, item["_sort_key"].(string))
+		}
+		return sks
+	}
+	assert.Equal(t, []string{"current_0", "current_1", "current_2"}, query())
+	assert.Equal(t, []string{"current_2", "current_1", "current_0"}, query("-desc"))
+
+	var stdout, stderr bytes.Buffer
+	err := run(context.Background(), []string{"scan", "-table", "test-table-1", "-desc"}, nil, &stdout, &stderr)
+	assert.Error(t, err)
+	assert.Contains(t, stderr.String(), "flag provided but not defined: -desc")
+}
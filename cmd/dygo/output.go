@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// writeResults writes the items of a query or scan and, if there are more pages,
+// the key to pass to -start-key to read the next page.
+func writeResults(stdout, stderr io.Writer, format string, results []map[string]types.AttributeValue, lastKey map[string]types.AttributeValue, cfg config) error {
+	items := make([]map[string]any, len(results))
+	for n, result := range results {
+		items[n] = plainItem(result)
+	}
+	if err := writeJSONItems(stdout, format, items, cfg); err != nil {
+		return err
+	}
+	if len(lastKey) > 0 {
+		next, err := json.Marshal(plainItem(lastKey))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stderr, "more items available, continue with -start-key '%s'\n", next)
+	}
+	return nil
+}
+
+// writeJSONItems writes the items as JSON lines or as a table.
+func writeJSONItems(w io.Writer, format string, items []map[string]any, cfg config) error {
+	switch format {
+	case "", "json":
+		enc := json.NewEncoder(w)
+		for _, item := range items {
+			if err := enc.Encode(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "table":
+		return writeTable(w, items, cfg)
+	}
+	return fmt.Errorf("unknown output format %q", format)
+}
+
+// writeTable writes the items as a table with a column per attribute, key attributes first.
+func writeTable(w io.Writer, items []map[string]any, cfg config) error {
+	first := map[string]int{cfg.PartitionKey: 1, cfg.SortKey: 2}
+	seen := make(map[string]bool)
+	var columns []string
+	for _, item := range items {
+		for name := range item {
+			if !seen[name] {
+				seen[name] = true
+				columns = append(columns, name)
+			}
+		}
+	}
+	sort.Slice(columns, func(a, b int) bool {
+		ra, rb := first[columns[a]], first[columns[b]]
+		if ra != 0 || rb != 0 {
+			return ra != 0 && (rb == 0 || ra < rb)
+		}
+		return columns[a] < columns[b]
+	})
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(columns, "\t"))
+	for _, item := range items {
+		cells := make([]string, len(columns))
+		for n, name := range columns {
+			cells[n] = tableCell(item[name])
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+// tableCell formats an attribute value for a table cell.
+func tableCell(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+// plainItem converts an item to plain JSON values, keeping numbers exact.
+func plainItem(item map[string]types.AttributeValue) map[string]any {
+	out := make(map[string]any, len(item))
+	for name, av := range item {
+		out[name] = plainValue(av)
+	}
+	return out
+}
+
+func plainValue(av types.AttributeValue) any {
+	switch v := av.(type) {
+	case *types.AttributeValueMemberS:
+		return v.Value
+	case *types.AttributeValueMemberN:
+		return json.Number(v.Value)
+	case *types.AttributeValueMemberB:
+		return base64.StdEncoding.EncodeToString(v.Value)
+	case *types.AttributeValueMemberBOOL:
+		return v.Value
+	case *types.AttributeValueMemberSS:
+		return v.Value
+	case *types.AttributeValueMemberNS:
+		numbers := make([]json.Number, len(v.Value))
+		for n, s := range v.Value {
+			numbers[n] = json.Number(s)
+		}
+		return numbers
+	case *types.AttributeValueMemberBS:
+		return v.Value
+	case *types.AttributeValueMemberL:
+		list := make([]any, len(v.Value))
+		for n, elem := range v.Value {
+			list[n] = plainValue(elem)
+		}
+		return list
+	case *types.AttributeValueMemberM:
+		return plainItem(v.Value)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package dygo
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// defaultCompressionThreshold is the size in bytes below which values tagged for compression are stored uncompressed.
+const defaultCompressionThreshold = 1024
+
+// Codec compresses and decompresses attribute values tagged with `dygo:"compress"`.
+// The name of the codec is stored with every compressed value, so it must be unique and stable.
+type Codec interface {
+	Name() string
+	Compress(data []byte) ([]byte, error)
+	Decompress(data []byte) ([]byte, error)
+}
+
+// GzipCodec is a Codec using gzip compression. It is the default codec of the client.
+type GzipCodec struct {
+	// Level is the gzip compression level, zero selects gzip.DefaultCompression.
+	Level int
+}
+
+// Name returns the name of the codec.
+func (g GzipCodec) Name() string {
+	return "gzip"
+}
+
+// Compress compresses data with gzip.
+func (g GzipCodec) Compress(data []byte) ([]byte, error) {
+	level := g.Level
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+	var buf bytes.Buffer
+	w, err := gzip.NewWriterLevel(&buf, level)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decompress decompresses gzip compressed data.
+func (g GzipCodec) Decompress(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// compressionCodec returns the codec used for writes.
+func (c *Client) compressionCodec() Codec {
+	if c.compression == nil {
+		return GzipCodec{}
+	}
+	return c.compression
+}
+
+// compressionMinSize returns the size in bytes from which values tagged for compression are compressed.
+func (c *Client) compressionMinSize() int {
+	if c.compressionThreshold <= 0 {
+		return defaultCompressionThreshold
+	}
+	return c.compressionThreshold
+}
+
+// compress compresses data with the configured codec and wraps it into an envelope.
+// The returned envelope layout is: magic | 'z' | len(codec name) | codec name | compressed data.
+func (c *Client) compress(data []byte) ([]byte, error) {
+	codec := c.compressionCodec()
+	name := codec.Name()
+	if len(name) == 0 || len(name) > 255 {
+		return nil, fmt.Errorf("invalid codec name %q", name)
+	}
+	compressed, err := codec.Compress(data)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, len(envelopeMagic)+2+len(name)+len(compressed))
+	out = append(out, envelopeMagic...)
+	out = append(out, envelopeCompressed, byte(len(name)))
+	out = append(out, name...)
+	return append(out, compressed...), nil
+}
+
+// decompress decompresses the payload of a compressed envelope, i.e. everything after the envelope kind.
+func (c *Client) decompress(payload []byte) ([]byte, error) {
+	if len(payload) < 1 || len(payload) < 1+int(payload[0]) {
+		return nil, errors.New("malformed compressed attribute")
+	}
+	name := string(payload[1 : 1+int(payload[0])])
+	codec, ok := c.codecs[name]
+	if !ok {
+		if name != (GzipCodec{}).Name() {
+			return nil, fmt.Errorf("unknown codec %q", name)
+		}
+		codec = GzipCodec{}
+	}
+	return codec.Decompress(payload[1+int(payload[0]):])
+}
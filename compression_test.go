@@ -0,0 +1,107 @@
+package dygo
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type documentItem struct {
+	PK     string         `dynamodbav:"_partition_key"`
+	Body   map[string]any `dynamodbav:"body" dygo:"compress"`
+	Secret string         `dynamodbav:"secret" dygo:"compress,encrypt"`
+	Note   string         `dynamodbav:"note" dygo:"compress"`
+}
+
+func (d documentItem) Validate() error {
+	return nil
+}
+
+type noteItem struct {
+	PK   string `dynamodbav:"_partition_key"`
+	Note string `dynamodbav:"note" dygo:"compress"`
+}
+
+func (n noteItem) Validate() error {
+	return nil
+}
+
+// reverseCodec is a trivial Codec used to test pluggable codecs.
+type reverseCodec struct{}
+
+func (reverseCodec) Name() string { return "reverse" }
+
+func (reverseCodec) Compress(data []byte) ([]byte, error) {
+	out := bytes.Clone(data)
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return out, nil
+}
+
+func (r reverseCodec) Decompress(data []byte) ([]byte, error) {
+	return r.Compress(data)
+}
+
+func Test_compression_round_trip(t *testing.T) {
+	c := &Client{keyProvider: newTestKeyProvider(t, "k1")}
+	in := documentItem{
+		PK:     "doc-1",
+		Body:   map[string]any{"text": strings.Repeat("lorem ipsum ", 500)},
+		Secret: strings.Repeat("s", 2048),
+		Note:   "small",
+	}
+
+	av, err := c.marshalItem(context.Background(), in)
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	body, ok := av["body"].(*types.AttributeValueMemberB)
+	if !ok {
+		t.Fatalf("expected binary attribute, got : %T", av["body"])
+	}
+	assert.Less(t, len(body.Value), 1024)
+	assert.Equal(t, envelopeCompressed, body.Value[len(envelopeMagic)])
+	assert.Equal(t, envelopeEncrypted, av["secret"].(*types.AttributeValueMemberB).Value[len(envelopeMagic)])
+	// values below the threshold are left untouched
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "small"}, av["note"])
+
+	if err := c.decodeItem(context.Background(), av); err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	var out documentItem
+	if err := attributevalue.UnmarshalMap(av, &out); err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	assert.Equal(t, in, out)
+}
+
+func Test_compression_custom_codec(t *testing.T) {
+	writer := &Client{}
+	if err := WithCompression(reverseCodec{}, 1)(writer); err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	av, err := writer.marshalItem(context.Background(), noteItem{PK: "doc-1", Note: "hello"})
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	assert.IsType(t, &types.AttributeValueMemberB{}, av["note"])
+
+	// readers need the codec to be registered
+	encoded := map[string]types.AttributeValue{"note": av["note"]}
+	assert.Error(t, (&Client{}).decodeItem(context.Background(), encoded))
+
+	reader := &Client{}
+	if err := WithCodec(reverseCodec{})(reader); err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	if err := reader.decodeItem(context.Background(), av); err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "hello"}, av["note"])
+}
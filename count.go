@@ -34,7 +34,7 @@ func (i *Item) Count(ctx context.Context) (int, int, error) {
 
 	expr, err := i.getQueryExpression()
 	if err != nil {
-		return totalCount, filteredCount, dynamoError().method(opCount).message(err.Error())
+		return totalCount, filteredCount, dynamoError().method(opCount).wrap(err)
 	}
 
 	input := dynamodb.QueryInput{
@@ -61,10 +61,7 @@ func (i *Item) getAllPages(ctx context.Context, input *dynamodb.QueryInput) (int
 	for paginator.HasMorePages() {
 		output, err := paginator.NextPage(ctx)
 		if err != nil {
-			if err := getDynamoDBError(opCount, err); err != nil {
-				return totalCount, filteredCount, err
-			}
-			return totalCount, filteredCount, dynamoError().method(opCount).message(err.Error())
+			return totalCount, filteredCount, i.opError(opCount, err)
 		}
 		totalCount += int(output.ScannedCount)
 		filteredCount += int(output.Count)
@@ -4,7 +4,6 @@ import (
 	"context"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 )
 
@@ -33,20 +32,25 @@ func (i *Item) Create(ctx context.Context) error {
 		return i.err
 	}
 
-	av, err := attributevalue.MarshalMap(i.item)
+	err := i.item.Validate()
 	if err != nil {
-		return dynamoError().method(opCreate).message(err.Error())
+		return validationError().method(opCreate).wrap(err)
 	}
 
-	err = i.item.Validate()
+	expr, err := i.createItemExpression()
 	if err != nil {
-		return dynamoError().method(opCreate).message(err.Error())
+		return dynamoError().method(opCreate).wrap(err)
 	}
 
-	expr, err := i.createItemExpression()
+	av, err := i.c.marshalItem(ctx, i.item)
 	if err != nil {
-		return dynamoError().method(opCreate).message(err.Error())
+		return dynamoError().method(opCreate).wrap(err)
 	}
+
+	if err := i.c.checkItemSize(av); err != nil {
+		return dynamoError().method(opCreate).wrap(joinCleanupError(err, i.c.deleteBlobs(ctx, blobKeys(av))))
+	}
+
 	input := dynamodb.PutItemInput{
 		Item:                      av,
 		TableName:                 aws.String(i.c.tableName),
@@ -55,11 +59,10 @@ func (i *Item) Create(ctx context.Context) error {
 		ConditionExpression:       expr.Condition(),
 	}
 
-	if _, err := i.c.client.PutItem(context.TODO(), &input); err != nil {
-		if err := getDynamoDBError(opCreate, err); err != nil {
-			return err
-		}
-		return dynamoError().method(opCreate).message(err.Error())
+	if _, err := i.c.client.PutItem(ctx, &input); err != nil {
+		// the item was not written, so the blobs offloaded for it are orphans
+		return joinCleanupError(i.opError(opCreate, err), i.c.deleteBlobs(ctx, blobKeys(av)))
 	}
+	i.c.invalidateCache(i.c.tableName, i.c.primaryKey(av))
 	return nil
 }
@@ -0,0 +1,322 @@
+package dygo
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const opLoadCSV = "LoadCSV"
+
+const defaultCSVChunkSize = 250
+
+// ColumnType is the type a CSV column is coerced to.
+type ColumnType string
+
+const (
+	// ColumnString stores the value as a string. It is the default.
+	ColumnString ColumnType = "string"
+	// ColumnNumber stores the value as a number, keeping its precision.
+	ColumnNumber ColumnType = "number"
+	// ColumnBool stores the value as a boolean, accepting the values of strconv.ParseBool.
+	ColumnBool ColumnType = "bool"
+	// ColumnTime parses the value with the layout of the column, time.RFC3339 by default,
+	// and stores it the way attributevalue stores time.Time.
+	ColumnTime ColumnType = "time"
+	// ColumnList splits the value at the separator given as layout of the column, "," by default,
+	// and stores it as a list of strings.
+	ColumnList ColumnType = "list"
+)
+
+// templatePlaceholder matches the {column} placeholders of key templates.
+var templatePlaceholder = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// numberPattern matches the numbers accepted by DynamoDB: decimal digits with an optional sign, fraction and exponent.
+// It excludes the NaN, Inf, hexadecimal and underscore forms accepted by strconv.ParseFloat.
+var numberPattern = regexp.MustCompile(`^[+-]?(\d+\.?\d*|\.\d+)([eE][+-]?\d+)?$`)
+
+// CSVMapping maps the columns of a CSV file to the attributes of items.
+type CSVMapping struct {
+	// Columns maps CSV columns to attributes. Columns without a mapping are ignored.
+	Columns []ColumnMapping
+	// Templates builds string attributes from several columns, e.g. "_partition_key": "{type}#{id}".
+	// Placeholders refer to CSV column names and are replaced with the raw column values.
+	Templates map[string]string
+	// Comma is the field delimiter, ',' by default.
+	Comma rune
+	// ChunkSize is the number of rows written at once, 250 by default.
+	ChunkSize int
+}
+
+// ColumnMapping maps a CSV column to an attribute.
+type ColumnMapping struct {
+	// Column is the name of the column in the header row.
+	Column string
+	// Attribute is the name of the attribute, the column name by default.
+	Attribute string
+	// Type is the type the value is coerced to, ColumnString by default.
+	Type ColumnType
+	// Layout is the time layout of ColumnTime or the separator of ColumnList.
+	Layout string
+	// Required rejects rows where the column is empty. Empty optional columns are left out of the item.
+	Required bool
+}
+
+// CSVReport is the outcome of LoadCSV.
+type CSVReport struct {
+	// Header is the header row of the CSV file.
+	Header []string
+	// Rows is the number of data rows read.
+	Rows int
+	// Loaded is the number of rows written to the table.
+	Loaded int
+	// Rejected lists the rows that were not written.
+	Rejected []RejectedRow
+}
+
+// RejectedRow is a CSV row that failed coercion or validation.
+type RejectedRow struct {
+	// Line is the line number of the row in the CSV file.
+	Line   int
+	Record []string
+	Reason string
+}
+
+// csvRow is a row that passed coercion and validation.
+type csvRow struct {
+	line   int
+	record []string
+	item   map[string]types.AttributeValue
+}
+
+// LoadCSV reads a CSV file with a header row from r, maps each row to an item of type T and writes the
+// valid items with BatchUpsertItem. Every row is validated with the Validate method of T.
+// Rows failing coercion or validation are collected in the report instead of aborting the load;
+// an error is only returned if the file can't be read or a batch can't be written.
+//
+// Example:
+//
+//	report, err := LoadCSV[dataItem](context.Background(), db, f, CSVMapping{
+//		Columns: []ColumnMapping{
+//			{Column: "name", Attribute: "physical_name", Required: true},
+//			{Column: "type", Attribute: "_entity_type"},
+//			{Column: "version", Type: ColumnNumber},
+//		},
+//		Templates: map[string]string{
+//			"_partition_key": "{type}#{id}",
+//			"_sort_key":      "current",
+//		},
+//	}, 10)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	report.WriteRejected(os.Stderr)
+func LoadCSV[T ItemData](ctx context.Context, c *Client, r io.Reader, mapping CSVMapping, threadCount int) (*CSVReport, error) {
+	reader := csv.NewReader(r)
+	if mapping.Comma != 0 {
+		reader.Comma = mapping.Comma
+	}
+	reader.FieldsPerRecord = -1
+	chunkSize := mapping.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = defaultCSVChunkSize
+	}
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, dynamoError().method(opLoadCSV).message(fmt.Sprintf("failed to read header: %v", err))
+	}
+	report := &CSVReport{Header: header}
+	columns := make(map[string]int, len(header))
+	for n, name := range header {
+		columns[strings.TrimSpace(name)] = n
+	}
+	for _, col := range mapping.Columns {
+		if _, ok := columns[col.Column]; !ok {
+			return report, validationError().method(opLoadCSV).message(fmt.Sprintf("column %q is not in the header", col.Column))
+		}
+	}
+	for attr, template := range mapping.Templates {
+		for _, m := range templatePlaceholder.FindAllStringSubmatch(template, -1) {
+			if _, ok := columns[m[1]]; !ok {
+				return report, validationError().method(opLoadCSV).message(fmt.Sprintf("template of %q refers to unknown column %q", attr, m[1]))
+			}
+		}
+	}
+
+	var chunk []csvRow
+	keys := make(map[string]bool)
+	flush := func() error {
+		if len(chunk) == 0 {
+			return nil
+		}
+		batch := new(Item)
+		for _, row := range chunk {
+			c.ItemRaw(row.item).AddBatchUpsertRawItem(batch)
+		}
+		if err := batch.BatchUpsertItem(ctx, threadCount); err != nil {
+			return err
+		}
+		report.Loaded += len(chunk)
+		chunk = chunk[:0]
+		keys = make(map[string]bool)
+		return nil
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			report.Rows++
+			report.Rejected = append(report.Rejected, RejectedRow{Line: parseErr.Line, Record: record, Reason: parseErr.Err.Error()})
+			continue
+		}
+		if err != nil {
+			return report, dynamoError().method(opLoadCSV).wrap(err)
+		}
+		report.Rows++
+		line, _ := reader.FieldPos(0)
+		if len(record) != len(header) {
+			report.Rejected = append(report.Rejected, RejectedRow{Line: line, Record: record, Reason: fmt.Sprintf("expected %d fields, got %d", len(header), len(record))})
+			continue
+		}
+
+		item, err := csvRowItem[T](ctx, c, mapping, columns, record)
+		if err != nil {
+			report.Rejected = append(report.Rejected, RejectedRow{Line: line, Record: record, Reason: err.Error()})
+			continue
+		}
+
+		// a batch can't write the same key twice, so a repeated key starts a new chunk and the last row wins
+		key := keyString(c.primaryKey(item))
+		if keys[key] || len(chunk) >= chunkSize {
+			if err := flush(); err != nil {
+				return report, dynamoError().method(opLoadCSV).wrap(err)
+			}
+		}
+		keys[key] = true
+		chunk = append(chunk, csvRow{line: line, record: record, item: item})
+	}
+
+	if err := flush(); err != nil {
+		return report, dynamoError().method(opLoadCSV).wrap(err)
+	}
+	return report, nil
+}
+
+// csvRowItem maps a CSV record to an item of type T, validates it and marshals it for writing.
+func csvRowItem[T ItemData](ctx context.Context, c *Client, mapping CSVMapping, columns map[string]int, record []string) (map[string]types.AttributeValue, error) {
+	av := make(map[string]types.AttributeValue, len(mapping.Columns)+len(mapping.Templates))
+	for _, col := range mapping.Columns {
+		attr := col.Attribute
+		if attr == "" {
+			attr = col.Column
+		}
+		value := strings.TrimSpace(record[columns[col.Column]])
+		if value == "" {
+			if col.Required {
+				return nil, fmt.Errorf("column %q is required", col.Column)
+			}
+			continue
+		}
+		v, err := coerceColumn(col, value)
+		if err != nil {
+			return nil, fmt.Errorf("column %q: %w", col.Column, err)
+		}
+		av[attr] = v
+	}
+	for attr, template := range mapping.Templates {
+		value := templatePlaceholder.ReplaceAllStringFunc(template, func(placeholder string) string {
+			return strings.TrimSpace(record[columns[placeholder[1:len(placeholder)-1]]])
+		})
+		av[attr] = &types.AttributeValueMemberS{Value: value}
+	}
+
+	var out T
+	if err := attributevalue.UnmarshalMap(av, &out); err != nil {
+		return nil, err
+	}
+	if err := out.Validate(); err != nil {
+		return nil, err
+	}
+
+	item, err := c.marshalItem(ctx, out)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkItemSize(item); err != nil {
+		return nil, joinCleanupError(err, c.deleteBlobs(ctx, blobKeys(item)))
+	}
+	return item, nil
+}
+
+// coerceColumn converts the raw value of a column to an attribute value of the column type.
+func coerceColumn(col ColumnMapping, value string) (types.AttributeValue, error) {
+	switch col.Type {
+	case "", ColumnString:
+		return &types.AttributeValueMemberS{Value: value}, nil
+	case ColumnNumber:
+		if !numberPattern.MatchString(value) {
+			return nil, fmt.Errorf("%q is not a number", value)
+		}
+		return &types.AttributeValueMemberN{Value: value}, nil
+	case ColumnBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a boolean", value)
+		}
+		return &types.AttributeValueMemberBOOL{Value: b}, nil
+	case ColumnTime:
+		layout := col.Layout
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		t, err := time.Parse(layout, value)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a time in layout %q", value, layout)
+		}
+		return attributevalue.Marshal(t)
+	case ColumnList:
+		separator := col.Layout
+		if separator == "" {
+			separator = ","
+		}
+		parts := strings.Split(value, separator)
+		list := make([]types.AttributeValue, len(parts))
+		for n, part := range parts {
+			list[n] = &types.AttributeValueMemberS{Value: strings.TrimSpace(part)}
+		}
+		return &types.AttributeValueMemberL{Value: list}, nil
+	}
+	return nil, fmt.Errorf("unknown column type %q", col.Type)
+}
+
+// WriteRejected writes the rejected rows as CSV to w: the header row followed by the rejected rows,
+// each with its line number and the reason of the rejection appended.
+func (r *CSVReport) WriteRejected(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	header := append(append([]string{}, r.Header...), "_line", "_error")
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, row := range r.Rejected {
+		record := append(append([]string{}, row.Record...), strconv.Itoa(row.Line), row.Reason)
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,133 @@
+package dygo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type csvRoom struct {
+	PK       string    `dynamodbav:"_partition_key"`
+	SK       string    `dynamodbav:"_sort_key"`
+	Name     string    `dynamodbav:"name"`
+	Capacity int       `dynamodbav:"capacity"`
+	Active   bool      `dynamodbav:"active"`
+	OpenedAt time.Time `dynamodbav:"opened_at"`
+	Tags     []string  `dynamodbav:"tags"`
+}
+
+func (r csvRoom) Validate() error {
+	if r.Capacity > 100 {
+		return errors.New("capacity must not exceed 100")
+	}
+	return nil
+}
+
+var csvRoomMapping = CSVMapping{
+	Columns: []ColumnMapping{
+		{Column: "name", Required: true},
+		{Column: "capacity", Type: ColumnNumber},
+		{Column: "active", Type: ColumnBool},
+		{Column: "opened", Attribute: "opened_at", Type: ColumnTime, Layout: "2006-01-02"},
+		{Column: "tags", Type: ColumnList, Layout: "|"},
+	},
+	Templates: map[string]string{
+		"_partition_key": "{type}#{id}",
+		"_sort_key":      "current",
+	},
+}
+
+func Test_load_csv(t *testing.T) {
+	fake := newFakeDynamoDB()
+	c := newFakeClient(t, fake)
+	input := strings.Join([]string{
+		"type,id,name,capacity,active,opened,tags",
+		"rm,1,Main hall,80,true,2023-04-01,large|ground floor",
+		"rm,2,,10,true,2023-04-01,",
+		"rm,3,Attic,many,false,2023-04-01,",
+		"rm,4,Ballroom,500,true,2023-04-01,",
+		"rm,5,Library,20,yes,2023-04-01,",
+		"rm,6,Kitchen",
+		"rm,1,Main hall renamed,80,true,2023-04-01,",
+	}, "\n")
+
+	report, err := LoadCSV[csvRoom](context.Background(), c, strings.NewReader(input), csvRoomMapping, 2)
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	assert.Equal(t, 7, report.Rows)
+	assert.Equal(t, 2, report.Loaded)
+
+	lines := make([]int, len(report.Rejected))
+	for n, row := range report.Rejected {
+		lines[n] = row.Line
+	}
+	assert.Equal(t, []int{3, 4, 5, 6, 7}, lines)
+	assert.Contains(t, report.Rejected[0].Reason, "required")
+	assert.Contains(t, report.Rejected[2].Reason, "capacity must not exceed 100")
+
+	item := fake.get("test-table-1", map[string]types.AttributeValue{
+		"_partition_key": &types.AttributeValueMemberS{Value: "rm#1"},
+		"_sort_key":      &types.AttributeValueMemberS{Value: "current"},
+	})
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "Main hall renamed"}, item["name"])
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "80"}, item["capacity"])
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "2023-04-01T00:00:00Z"}, item["opened_at"])
+
+	var buf bytes.Buffer
+	if err := report.WriteRejected(&buf); err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	out := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, out, 6)
+	assert.Equal(t, "type,id,name,capacity,active,opened,tags,_line,_error", out[0])
+}
+
+func Test_load_csv_invalid_mapping(t *testing.T) {
+	c := &Client{tableName: "test-table-1", partitionKey: "_partition_key", sortKey: "_sort_key"}
+	mapping := CSVMapping{Templates: map[string]string{"_partition_key": "{kind}#{id}"}}
+	_, err := LoadCSV[csvRoom](context.Background(), c, strings.NewReader("type,id\nrm,1\n"), mapping, 1)
+	assert.True(t, errors.Is(err, ErrValidation), err)
+	assert.Contains(t, err.Error(), `unknown column "kind"`)
+}
+
+func Test_coerce_number_column(t *testing.T) {
+	tests := []struct {
+		value string
+		valid bool
+	}{
+		{"80", true},
+		{"-1.5", true},
+		{"+.5", true},
+		{"1.", true},
+		{"1.5e-3", true},
+		{"12E+10", true},
+		{"", false},
+		{"NaN", false},
+		{"Inf", false},
+		{"-infinity", false},
+		{"0x1p4", false},
+		{"1_000", false},
+		{"1e", false},
+		{".", false},
+		{"1.2.3", false},
+	}
+	col := ColumnMapping{Column: "capacity", Type: ColumnNumber}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			v, err := coerceColumn(col, tt.value)
+			if !tt.valid {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, &types.AttributeValueMemberN{Value: tt.value}, v)
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 )
 
 const opDelete = "Delete"
@@ -19,7 +20,7 @@ func (i *Item) Delete(ctx context.Context) error {
 
 	expr, err := i.deleteItemExpression()
 	if err != nil {
-		return dynamoError().method(opDelete).message(err.Error())
+		return dynamoError().method(opDelete).wrap(err)
 	}
 
 	input := dynamodb.DeleteItemInput{
@@ -29,11 +30,18 @@ func (i *Item) Delete(ctx context.Context) error {
 		ExpressionAttributeValues: expr.Values(),
 		ConditionExpression:       expr.Condition(),
 	}
-	if _, err := i.c.client.DeleteItem(context.TODO(), &input); err != nil {
-		if err := getDynamoDBError(opDelete, err); err != nil {
-			return err
-		}
-		return dynamoError().method(opDelete).message(err.Error())
+	if i.c.blobStore != nil {
+		// the deleted item is needed to clean up the blobs it referenced
+		input.ReturnValues = types.ReturnValueAllOld
+	}
+	output, err := i.c.client.DeleteItem(ctx, &input)
+	if err != nil {
+		return i.opError(opDelete, err)
+	}
+	i.c.invalidateCache(i.c.tableName, i.key)
+
+	if err := i.c.deleteBlobs(ctx, blobKeys(output.Attributes)); err != nil {
+		return dynamoError().method(opDelete).wrap(err)
 	}
 	return nil
 }
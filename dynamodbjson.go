@@ -0,0 +1,188 @@
+package dygo
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// marshalDynamoDBJSON encodes an attribute value into the DynamoDB JSON format, e.g. {"S":"value"}.
+func marshalDynamoDBJSON(av types.AttributeValue) ([]byte, error) {
+	v, err := toDynamoDBJSONValue(av)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// unmarshalDynamoDBJSON decodes an attribute value from the DynamoDB JSON format.
+func unmarshalDynamoDBJSON(data []byte) (types.AttributeValue, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	return fromDynamoDBJSONValue(raw)
+}
+
+// toDynamoDBJSONValue converts an attribute value into a value that encoding/json marshals as DynamoDB JSON.
+func toDynamoDBJSONValue(av types.AttributeValue) (map[string]any, error) {
+	switch v := av.(type) {
+	case *types.AttributeValueMemberS:
+		return map[string]any{"S": v.Value}, nil
+	case *types.AttributeValueMemberN:
+		return map[string]any{"N": v.Value}, nil
+	case *types.AttributeValueMemberB:
+		return map[string]any{"B": base64.StdEncoding.EncodeToString(v.Value)}, nil
+	case *types.AttributeValueMemberBOOL:
+		return map[string]any{"BOOL": v.Value}, nil
+	case *types.AttributeValueMemberNULL:
+		return map[string]any{"NULL": v.Value}, nil
+	case *types.AttributeValueMemberSS:
+		return map[string]any{"SS": v.Value}, nil
+	case *types.AttributeValueMemberNS:
+		return map[string]any{"NS": v.Value}, nil
+	case *types.AttributeValueMemberBS:
+		values := make([]string, len(v.Value))
+		for i, b := range v.Value {
+			values[i] = base64.StdEncoding.EncodeToString(b)
+		}
+		return map[string]any{"BS": values}, nil
+	case *types.AttributeValueMemberL:
+		values := make([]any, len(v.Value))
+		for i, elem := range v.Value {
+			e, err := toDynamoDBJSONValue(elem)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = e
+		}
+		return map[string]any{"L": values}, nil
+	case *types.AttributeValueMemberM:
+		values := make(map[string]any, len(v.Value))
+		for k, elem := range v.Value {
+			e, err := toDynamoDBJSONValue(elem)
+			if err != nil {
+				return nil, err
+			}
+			values[k] = e
+		}
+		return map[string]any{"M": values}, nil
+	}
+	return nil, fmt.Errorf("unsupported attribute value type %T", av)
+}
+
+// fromDynamoDBJSONValue converts a decoded DynamoDB JSON object into an attribute value.
+func fromDynamoDBJSONValue(raw map[string]json.RawMessage) (types.AttributeValue, error) {
+	if len(raw) != 1 {
+		return nil, errors.New("dynamodb json value must have exactly one type descriptor")
+	}
+	for typ, data := range raw {
+		switch typ {
+		case "S":
+			var s string
+			err := json.Unmarshal(data, &s)
+			return &types.AttributeValueMemberS{Value: s}, err
+		case "N":
+			var n string
+			err := json.Unmarshal(data, &n)
+			return &types.AttributeValueMemberN{Value: n}, err
+		case "B":
+			var s string
+			if err := json.Unmarshal(data, &s); err != nil {
+				return nil, err
+			}
+			b, err := base64.StdEncoding.DecodeString(s)
+			return &types.AttributeValueMemberB{Value: b}, err
+		case "BOOL":
+			var b bool
+			err := json.Unmarshal(data, &b)
+			return &types.AttributeValueMemberBOOL{Value: b}, err
+		case "NULL":
+			var b bool
+			err := json.Unmarshal(data, &b)
+			return &types.AttributeValueMemberNULL{Value: b}, err
+		case "SS":
+			var ss []string
+			err := json.Unmarshal(data, &ss)
+			return &types.AttributeValueMemberSS{Value: ss}, err
+		case "NS":
+			var ns []string
+			err := json.Unmarshal(data, &ns)
+			return &types.AttributeValueMemberNS{Value: ns}, err
+		case "BS":
+			var encoded []string
+			if err := json.Unmarshal(data, &encoded); err != nil {
+				return nil, err
+			}
+			bs := make([][]byte, len(encoded))
+			for i, s := range encoded {
+				b, err := base64.StdEncoding.DecodeString(s)
+				if err != nil {
+					return nil, err
+				}
+				bs[i] = b
+			}
+			return &types.AttributeValueMemberBS{Value: bs}, nil
+		case "L":
+			var elems []map[string]json.RawMessage
+			if err := json.Unmarshal(data, &elems); err != nil {
+				return nil, err
+			}
+			l := make([]types.AttributeValue, len(elems))
+			for i, elem := range elems {
+				av, err := fromDynamoDBJSONValue(elem)
+				if err != nil {
+					return nil, err
+				}
+				l[i] = av
+			}
+			return &types.AttributeValueMemberL{Value: l}, nil
+		case "M":
+			var elems map[string]map[string]json.RawMessage
+			if err := json.Unmarshal(data, &elems); err != nil {
+				return nil, err
+			}
+			m := make(map[string]types.AttributeValue, len(elems))
+			for k, elem := range elems {
+				av, err := fromDynamoDBJSONValue(elem)
+				if err != nil {
+					return nil, err
+				}
+				m[k] = av
+			}
+			return &types.AttributeValueMemberM{Value: m}, nil
+		default:
+			return nil, fmt.Errorf("unknown dynamodb json type descriptor %q", typ)
+		}
+	}
+	return nil, nil
+}
+
+// toDynamoDBJSONItem converts an item into a value that encoding/json marshals as DynamoDB JSON.
+func toDynamoDBJSONItem(item map[string]types.AttributeValue) (map[string]any, error) {
+	out := make(map[string]any, len(item))
+	for name, av := range item {
+		v, err := toDynamoDBJSONValue(av)
+		if err != nil {
+			return nil, fmt.Errorf("attribute %q: %w", name, err)
+		}
+		out[name] = v
+	}
+	return out, nil
+}
+
+// fromDynamoDBJSONItem converts a decoded DynamoDB JSON item into attribute values.
+func fromDynamoDBJSONItem(raw map[string]map[string]json.RawMessage) (map[string]types.AttributeValue, error) {
+	out := make(map[string]types.AttributeValue, len(raw))
+	for name, data := range raw {
+		av, err := fromDynamoDBJSONValue(data)
+		if err != nil {
+			return nil, fmt.Errorf("attribute %q: %w", name, err)
+		}
+		out[name] = av
+	}
+	return out, nil
+}
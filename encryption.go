@@ -0,0 +1,142 @@
+package dygo
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// KeyProvider supplies the keys used for client-side field-level encryption.
+// Every encrypted attribute stores the ID of the key it was encrypted with, so keys can be rotated
+// by switching the encryption key while older keys remain available for decryption.
+type KeyProvider interface {
+	// EncryptionKey returns the ID and the key that new writes are encrypted with.
+	EncryptionKey(ctx context.Context) (keyID string, key []byte, err error)
+	// DecryptionKey returns the key identified by keyID.
+	DecryptionKey(ctx context.Context, keyID string) ([]byte, error)
+}
+
+// LocalKeyProvider is a KeyProvider that holds AES keys in memory.
+type LocalKeyProvider struct {
+	currentKeyID string
+	keys         map[string][]byte
+}
+
+// NewLocalKeyProvider returns a KeyProvider backed by the given AES keys.
+// currentKeyID selects the key used for new writes, all keys remain usable for decryption.
+// Keys must be 16, 24 or 32 bytes long to select AES-128, AES-192 or AES-256.
+//
+// Example:
+//
+//	kp, err := NewLocalKeyProvider("2024-01", map[string][]byte{
+//		"2023-01": oldKey,
+//		"2024-01": newKey,
+//	})
+//	db, err := NewClient(
+//		WithTableName("test-table-1"),
+//		WithRegion("ap-northeast-1"),
+//		WithPartitionKey("_partition_key"),
+//		WithKeyProvider(kp),
+//	)
+func NewLocalKeyProvider(currentKeyID string, keys map[string][]byte) (*LocalKeyProvider, error) {
+	if _, ok := keys[currentKeyID]; !ok {
+		return nil, fmt.Errorf("current key %q not found", currentKeyID)
+	}
+	p := &LocalKeyProvider{
+		currentKeyID: currentKeyID,
+		keys:         make(map[string][]byte, len(keys)),
+	}
+	for id, key := range keys {
+		if len(id) == 0 || len(id) > 255 {
+			return nil, fmt.Errorf("invalid key id %q", id)
+		}
+		switch len(key) {
+		case 16, 24, 32:
+		default:
+			return nil, fmt.Errorf("invalid key size %d for key %q", len(key), id)
+		}
+		p.keys[id] = append([]byte(nil), key...)
+	}
+	return p, nil
+}
+
+// EncryptionKey returns the current key and its ID.
+func (p *LocalKeyProvider) EncryptionKey(ctx context.Context) (string, []byte, error) {
+	return p.currentKeyID, p.keys[p.currentKeyID], nil
+}
+
+// DecryptionKey returns the key identified by keyID.
+func (p *LocalKeyProvider) DecryptionKey(ctx context.Context, keyID string) ([]byte, error) {
+	key, ok := p.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", keyID)
+	}
+	return key, nil
+}
+
+// encrypt seals plaintext with AES-GCM using the current key of the provider.
+// The attribute name is bound to the ciphertext as additional data so values can't be swapped between attributes.
+// The returned envelope layout is: magic | 'e' | len(keyID) | keyID | nonce | ciphertext.
+func encrypt(ctx context.Context, kp KeyProvider, attributeName string, plaintext []byte) ([]byte, error) {
+	keyID, key, err := kp.EncryptionKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(keyID) == 0 || len(keyID) > 255 {
+		return nil, fmt.Errorf("invalid key id %q", keyID)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, len(envelopeMagic)+2+len(keyID)+len(nonce)+len(plaintext)+gcm.Overhead())
+	out = append(out, envelopeMagic...)
+	out = append(out, envelopeEncrypted, byte(len(keyID)))
+	out = append(out, keyID...)
+	out = append(out, nonce...)
+	return gcm.Seal(out, nonce, plaintext, []byte(attributeName)), nil
+}
+
+// decrypt opens the payload of an encrypted envelope, i.e. everything after the envelope kind.
+func decrypt(ctx context.Context, kp KeyProvider, attributeName string, payload []byte) ([]byte, error) {
+	if kp == nil {
+		return nil, errors.New("encrypted attribute found but no key provider is configured")
+	}
+	if len(payload) < 1 || len(payload) < 1+int(payload[0]) {
+		return nil, errors.New("malformed encrypted attribute")
+	}
+	keyID := string(payload[1 : 1+int(payload[0])])
+	payload = payload[1+int(payload[0]):]
+
+	key, err := kp.DecryptionKey(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(payload) < gcm.NonceSize() {
+		return nil, errors.New("malformed encrypted attribute")
+	}
+	nonce, ciphertext := payload[:gcm.NonceSize()], payload[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, []byte(attributeName))
+}
+
+// newGCM returns an AES-GCM AEAD for the given key.
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,98 @@
+package dygo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type secretItem struct {
+	PK      string            `dynamodbav:"_partition_key"`
+	SK      string            `dynamodbav:"_sort_key"`
+	Email   string            `dynamodbav:"email" dygo:"encrypt"`
+	Profile map[string]string `dynamodbav:"profile" dygo:"encrypt"`
+	Age     int               `dynamodbav:"age"`
+}
+
+func (s secretItem) Validate() error {
+	return nil
+}
+
+func newTestKeyProvider(t *testing.T, current string) KeyProvider {
+	kp, err := NewLocalKeyProvider(current, map[string][]byte{
+		"k1": []byte("0123456789abcdef0123456789abcdef"),
+		"k2": []byte("fedcba9876543210fedcba9876543210"),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	return kp
+}
+
+func Test_encryption_round_trip(t *testing.T) {
+	c := &Client{keyProvider: newTestKeyProvider(t, "k1")}
+	in := secretItem{
+		PK:      "usr-1",
+		SK:      "current",
+		Email:   "someone@example.com",
+		Profile: map[string]string{"city": "Tokyo"},
+		Age:     42,
+	}
+
+	av, err := c.marshalItem(context.Background(), in)
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	assert.IsType(t, &types.AttributeValueMemberB{}, av["email"])
+	assert.IsType(t, &types.AttributeValueMemberB{}, av["profile"])
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "42"}, av["age"])
+	assert.NotContains(t, string(av["email"].(*types.AttributeValueMemberB).Value), in.Email)
+
+	if err := c.decodeItem(context.Background(), av); err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	var out secretItem
+	if err := attributevalue.UnmarshalMap(av, &out); err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	assert.Equal(t, in, out)
+}
+
+func Test_encryption_key_rotation(t *testing.T) {
+	writer := &Client{keyProvider: newTestKeyProvider(t, "k1")}
+	av, err := writer.marshalItem(context.Background(), secretItem{PK: "usr-1", Email: "someone@example.com"})
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+
+	reader := &Client{keyProvider: newTestKeyProvider(t, "k2")}
+	if err := reader.decodeItem(context.Background(), av); err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "someone@example.com"}, av["email"])
+}
+
+func Test_encryption_errors(t *testing.T) {
+	c := &Client{keyProvider: newTestKeyProvider(t, "k1")}
+	av, err := c.marshalItem(context.Background(), secretItem{PK: "usr-1", Email: "someone@example.com"})
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+
+	// ciphertext is bound to its attribute name
+	swapped := map[string]types.AttributeValue{"profile": av["email"]}
+	assert.Error(t, c.decodeItem(context.Background(), swapped))
+
+	// decryption requires a key provider
+	assert.Error(t, (&Client{}).decodeItem(context.Background(), map[string]types.AttributeValue{"email": av["email"]}))
+
+	// encryption requires a key provider
+	_, err = (&Client{}).marshalItem(context.Background(), secretItem{PK: "usr-1"})
+	assert.Error(t, err)
+
+	_, err = NewLocalKeyProvider("k1", map[string][]byte{"k1": []byte("short")})
+	assert.Error(t, err)
+}
@@ -3,75 +3,202 @@ package dygo
 import (
 	"fmt"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
 	"github.com/pkg/errors"
 )
 
-type dError struct {
-	Function     string
-	ErrorMessage error
-}
-
 const (
-	errDygoError           = "DygoError"
-	errMissingTableName    = "table name is missing"
-	errMissingPartitionKey = "partition key is missing"
-	errMissingRegion       = "region is missing"
-	errMissingClient       = "something went wrong while creating the client"
+	errDygoError            = "DygoError"
+	errMissingTableName     = "table name is missing"
+	errMissingPartitionKey  = "partition key is missing"
+	errMissingRegion        = "region is missing"
+	errMissingClient        = "something went wrong while creating the client"
+	errMissingBlindIndexKey = "blind index key is missing"
 )
 
-// Error returns the error message associated with the Error struct.
+// The kinds of failure of the operations, to be checked with errors.Is.
+var (
+	// ErrNotFound is returned, wrapped, when a requested item doesn't exist, by MustGetItem and by Delete.
+	ErrNotFound = errors.New("item not found")
+	// ErrConditionFailed is returned, wrapped, when the condition of a write isn't met.
+	ErrConditionFailed = errors.New("condition failed")
+	// ErrAlreadyExists is returned, wrapped, when Create finds an item with the same key.
+	ErrAlreadyExists = errors.New("item already exists")
+	// ErrThrottled is returned, wrapped, when DynamoDB throttles a request.
+	ErrThrottled = errors.New("request throttled")
+	// ErrValidation is returned, wrapped, when a request or an item is invalid.
+	ErrValidation = errors.New("validation failed")
+	// ErrUnauthorized is returned, wrapped, when the caller isn't allowed to access the items or the table.
+	ErrUnauthorized = errors.New("unauthorized")
+)
+
+// OpError is the error returned by the operations of the package.
+// It matches, with errors.Is, the sentinel error of its kind, such as ErrNotFound or ErrThrottled,
+// and the underlying AWS SDK error is available to errors.As.
+//
+// Example:
+//
+//	err := db.PK("room#1").SK(dygo.Equal("current")).Delete(ctx)
+//	if errors.Is(err, dygo.ErrNotFound) {
+//		...
+//	}
+//	var opErr *dygo.OpError
+//	if errors.As(err, &opErr) {
+//		log.Printf("%s on %s failed for key %v", opErr.Op, opErr.Table, opErr.Key)
+//	}
+type OpError struct {
+	// Op is the name of the failed operation.
+	Op string
+	// Table is the table of the operation, if known.
+	Table string
+	// Key is the primary key of the item of the operation, if known.
+	Key map[string]types.AttributeValue
+	// Kind is the sentinel error matching the failure, or nil.
+	Kind error
+	// Err is the cause of the failure.
+	Err error
+}
+
+// Error returns the error message associated with the OpError.
 // It formats the error message with the error type, method name, and error message.
-func (e *dError) Error() string {
-	return fmt.Sprintf("%s:: method : %s() message: %s", errDygoError, e.Function, e.ErrorMessage)
+func (e *OpError) Error() string {
+	return fmt.Sprintf("%s:: method : %s() message: %s", errDygoError, e.Op, e.Err)
+}
+
+// Unwrap returns the kind and the cause of the error.
+func (e *OpError) Unwrap() []error {
+	var errs []error
+	if e.Kind != nil {
+		errs = append(errs, e.Kind)
+	}
+	if e.Err != nil {
+		errs = append(errs, e.Err)
+	}
+	return errs
+}
+
+// DynamoError returns a new instance of OpError.
+func dynamoError() *OpError {
+	return &OpError{}
 }
 
-// DynamoError returns a new instance of Error.
-func dynamoError() *dError {
-	return &dError{}
+// validationError returns a new instance of OpError of kind ErrValidation.
+func validationError() *OpError {
+	return &OpError{Kind: ErrValidation}
 }
 
 // Method sets the function name associated with the error.
-func (e *dError) method(method string) *dError {
-	e.Function = method
+func (e *OpError) method(method string) *OpError {
+	e.Op = method
+	return e
+}
+
+// Message sets the error message for the OpError instance.
+func (e *OpError) message(msg string) *OpError {
+	e.Err = errors.New(msg)
+	return e
+}
+
+// wrap sets err as the cause of the OpError instance, keeping it available to errors.Is and errors.As.
+func (e *OpError) wrap(err error) *OpError {
+	e.Err = err
 	return e
 }
 
-// Message sets the error message for the Error instance.
-func (e *dError) message(msg string) *dError {
-	e.ErrorMessage = errors.New(msg)
+// kind sets the sentinel error matching the failure.
+func (e *OpError) kind(kind error) *OpError {
+	e.Kind = kind
 	return e
 }
 
-// getDynamoDBError returns a custom error based on the type of error encountered in DynamoDB operations.
-func getDynamoDBError(method string, err error) error {
-	method = fmt.Sprintf("%s()", method)
+// opError returns the error of the operation on the item for an error returned by DynamoDB, see getDynamoDBError.
+func (i *Item) opError(method string, err error) error {
+	return i.c.opError(method, i.key, err)
+}
+
+// notFoundError returns the error of the operation on the item when there is no item with its key.
+func (i *Item) notFoundError(method string) error {
+	return &OpError{Op: method, Table: i.c.tableName, Key: i.key, Kind: ErrNotFound, Err: ErrNotFound}
+}
+
+// opError returns the error of an operation on the table for an error returned by DynamoDB, see getDynamoDBError.
+func (c *Client) opError(method string, key map[string]types.AttributeValue, err error) error {
+	e := getDynamoDBError(method, err)
+	if e.Table == "" {
+		e.Table = c.tableName
+	}
+	if e.Key == nil && len(key) > 0 {
+		e.Key = key
+	}
+	return e
+}
+
+// getDynamoDBError returns the OpError of an error returned by DynamoDB, of the kind matching the DynamoDB exception.
+// A failed condition is reported as ErrNotFound by Delete, which requires the item to exist,
+// and as ErrAlreadyExists by Create and Import, which require it not to.
+func getDynamoDBError(method string, err error) *OpError {
+	e := dynamoError().method(method).wrap(err)
+	var oe *OpError
+	if errors.As(err, &oe) {
+		return oe
+	}
+
 	var cce *types.ConditionalCheckFailedException
 	var dce *types.DuplicateItemException
-	var infe *types.IndexNotFoundException
-	var ise *types.InternalServerError
-	var rnfe *types.ResourceNotFoundException
-	var tnfe *types.TableNotFoundException
+	var ptee *types.ProvisionedThroughputExceededException
+	var rlee *types.RequestLimitExceeded
+	var tce *types.TransactionCanceledException
+	var apiErr smithy.APIError
 
 	switch {
 	case errors.As(err, &cce):
-		if method == "Delete()" {
-			return dynamoError().method(method).message("key doesnt exist")
-		}
-		if method == "Create()" {
-			return dynamoError().method(method).message("duplicate item")
+		switch method {
+		case opDelete:
+			return e.kind(ErrNotFound)
+		case opCreate, opImport:
+			return e.kind(ErrAlreadyExists)
 		}
-		return dynamoError().method(method).message("key doesnt exist")
+		return e.kind(ErrConditionFailed)
 	case errors.As(err, &dce):
-		return dynamoError().method(method).message("duplicate item")
-	case errors.As(err, &infe):
-		return dynamoError().method(method).message("index doesnt exist")
-	case errors.As(err, &ise):
-		return dynamoError().method(method).message("internal server error")
-	case errors.As(err, &rnfe):
-		return dynamoError().method(method).message("table/index doesnt exist")
-	case errors.As(err, &tnfe):
-		return dynamoError().method(method).message("table doesnt exist")
+		return e.kind(ErrAlreadyExists)
+	case errors.As(err, &ptee), errors.As(err, &rlee):
+		return e.kind(ErrThrottled)
+	case errors.As(err, &tce):
+		for _, reason := range tce.CancellationReasons {
+			if kind := errorCodeKind(aws.ToString(reason.Code)); kind != nil {
+				return e.kind(kind)
+			}
+		}
+		return e
+	case errors.As(err, &apiErr):
+		switch apiErr.ErrorCode() {
+		case "ThrottlingException":
+			return e.kind(ErrThrottled)
+		case "ValidationException", "SerializationException":
+			return e.kind(ErrValidation)
+		case "AccessDeniedException", "UnrecognizedClientException", "MissingAuthenticationTokenException":
+			return e.kind(ErrUnauthorized)
+		}
+	}
+	return e
+}
+
+// errorCodeKind returns the sentinel error matching the error code of a statement of a batch
+// or the code of a cancellation reason of a transaction, or nil.
+func errorCodeKind(code string) error {
+	switch code {
+	case "ConditionalCheckFailed":
+		return ErrConditionFailed
+	case "DuplicateItem":
+		return ErrAlreadyExists
+	case "ThrottlingError", "ProvisionedThroughputExceeded", "RequestLimitExceeded":
+		return ErrThrottled
+	case "ValidationError":
+		return ErrValidation
+	case "AccessDenied":
+		return ErrUnauthorized
 	}
 	return nil
 }
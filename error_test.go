@@ -0,0 +1,77 @@
+package dygo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_operation_errors(t *testing.T) {
+	fake := newFakeDynamoDB()
+	c := newFakeClient(t, fake)
+	ctx := context.Background()
+	pk := newPK("room")
+	key := map[string]types.AttributeValue{
+		"_partition_key": &types.AttributeValueMemberS{Value: pk},
+		"_sort_key":      &types.AttributeValueMemberS{Value: "current"},
+	}
+	fake.put("test-table-1", map[string]types.AttributeValue{
+		"_partition_key": key["_partition_key"],
+		"_sort_key":      key["_sort_key"],
+		"_entity_type":   &types.AttributeValueMemberS{Value: "room"},
+	})
+
+	err := c.Item(dataItem{PK: pk, SK: "current", EntityType: "room"}).Create(ctx)
+	assert.True(t, errors.Is(err, ErrAlreadyExists))
+	var cce *types.ConditionalCheckFailedException
+	assert.True(t, errors.As(err, &cce))
+	var opErr *OpError
+	if assert.True(t, errors.As(err, &opErr)) {
+		assert.Equal(t, opCreate, opErr.Op)
+		assert.Equal(t, "test-table-1", opErr.Table)
+	}
+
+	err = c.PK(newPK("room")).SK(Equal("current")).Delete(ctx)
+	assert.True(t, errors.Is(err, ErrNotFound))
+	assert.False(t, errors.Is(err, ErrConditionFailed))
+	if assert.True(t, errors.As(err, &opErr)) {
+		assert.Equal(t, opDelete, opErr.Op)
+		assert.Equal(t, &types.AttributeValueMemberS{Value: "current"}, opErr.Key["_sort_key"])
+	}
+
+	err = c.Item(dataItem{PK: pk, SK: "current", EntityType: "hall"}).Upsert(ctx)
+	assert.True(t, errors.Is(err, ErrValidation))
+	assert.True(t, errors.Is(c.PK("").err, ErrValidation))
+
+	tests := []struct {
+		code string
+		want error
+	}{
+		{code: "ConditionalCheckFailedException", want: ErrConditionFailed},
+		{code: "ProvisionedThroughputExceededException", want: ErrThrottled},
+		{code: "ValidationException", want: ErrValidation},
+		{code: "AccessDeniedException", want: ErrUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			fake.hook = func(op string, req map[string]json.RawMessage) (int, any) {
+				return http.StatusBadRequest, fakeError(tt.code, "failed")
+			}
+			defer func() { fake.hook = nil }()
+
+			err := c.Item(dataItem{PK: pk, SK: "current", EntityType: "room"}).Upsert(ctx)
+			assert.True(t, errors.Is(err, tt.want), err)
+			if tt.want == ErrConditionFailed {
+				return
+			}
+			var out dataItem
+			err = c.PK(pk).SK(Equal("current")).GetItem(ctx, &out)
+			assert.True(t, errors.Is(err, tt.want), err)
+		})
+	}
+}
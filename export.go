@@ -0,0 +1,249 @@
+package dygo
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"golang.org/x/sync/errgroup"
+)
+
+const opExport = "Export"
+
+// Format is the line format of exported tables.
+type Format string
+
+const (
+	// FormatDynamoDBJSON writes one {"Item": {...}} object in DynamoDB JSON per line,
+	// the format of the DynamoDB export to S3. It preserves all attribute types.
+	FormatDynamoDBJSON Format = "dynamodb-json"
+	// FormatJSON writes one plain JSON object per line. Sets are written as arrays and binary values as base64 strings,
+	// so they are imported back as lists and strings.
+	FormatJSON Format = "json"
+)
+
+// ExportOptions configures Export.
+type ExportOptions struct {
+	// Format is the line format, FormatDynamoDBJSON by default.
+	Format Format
+	// Segments is the number of segments of the parallel scan, 1 by default.
+	Segments int
+	// Decode writes items decrypted, decompressed and with offloaded attributes rehydrated.
+	// By default items are written as stored, so an import restores them unchanged.
+	Decode bool
+	// ConsistentRead uses strongly consistent reads for the scan.
+	ConsistentRead bool
+	// Progress, if set, is called with the number of exported items after every scanned page.
+	Progress func(items int)
+}
+
+// exportLine is a line of a DynamoDB JSON export.
+type exportLine struct {
+	Item map[string]map[string]json.RawMessage
+}
+
+// Export scans the whole table and writes its items to w as JSON lines. It returns the number of exported items.
+// With several segments, lines are written in no particular order.
+//
+// Example:
+//
+//	f, err := os.Create("backup.jsonl")
+//	...
+//	n, err := db.Export(context.Background(), f, ExportOptions{Segments: 4})
+func (c *Client) Export(ctx context.Context, w io.Writer, opts ExportOptions) (int, error) {
+	segments := opts.Segments
+	if segments < 1 {
+		segments = 1
+	}
+	format := opts.Format
+	if format == "" {
+		format = FormatDynamoDBJSON
+	}
+	if format != FormatDynamoDBJSON && format != FormatJSON {
+		return 0, validationError().method(opExport).message(fmt.Sprintf("unknown format %q", format))
+	}
+
+	bw := bufio.NewWriter(w)
+	var mu sync.Mutex
+	var count int
+
+	g, ctx := errgroup.WithContext(ctx)
+	for segment := 0; segment < segments; segment++ {
+		input := &dynamodb.ScanInput{
+			TableName:      aws.String(c.tableName),
+			ConsistentRead: aws.Bool(opts.ConsistentRead),
+		}
+		if segments > 1 {
+			input.Segment = aws.Int32(int32(segment))
+			input.TotalSegments = aws.Int32(int32(segments))
+		}
+		g.Go(func() error {
+			paginator := dynamodb.NewScanPaginator(c.client, input)
+			for paginator.HasMorePages() {
+				page, err := paginator.NextPage(ctx)
+				if err != nil {
+					return err
+				}
+				if opts.Decode {
+					if err := c.decodeItems(ctx, page.Items); err != nil {
+						return err
+					}
+				}
+
+				var lines []byte
+				for _, item := range page.Items {
+					line, err := encodeExportLine(item, format)
+					if err != nil {
+						return err
+					}
+					lines = append(append(lines, line...), '\n')
+				}
+
+				mu.Lock()
+				_, err = bw.Write(lines)
+				count += len(page.Items)
+				if err == nil && opts.Progress != nil {
+					opts.Progress(count)
+				}
+				mu.Unlock()
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return count, dynamoError().method(opExport).wrap(err)
+	}
+	if err := bw.Flush(); err != nil {
+		return count, dynamoError().method(opExport).wrap(err)
+	}
+	return count, nil
+}
+
+// encodeExportLine encodes an item as a line of the format, without the trailing newline.
+func encodeExportLine(item map[string]types.AttributeValue, format Format) ([]byte, error) {
+	if format == FormatJSON {
+		out := make(map[string]any, len(item))
+		for name, av := range item {
+			out[name] = toPlainJSONValue(av)
+		}
+		return json.Marshal(out)
+	}
+	v, err := toDynamoDBJSONItem(item)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(map[string]any{"Item": v})
+}
+
+// decodeExportLine decodes an item from a line of the format.
+func decodeExportLine(line []byte, format Format) (map[string]types.AttributeValue, error) {
+	if format == FormatJSON {
+		dec := json.NewDecoder(bytes.NewReader(line))
+		dec.UseNumber()
+		var raw map[string]any
+		if err := dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+		item := make(map[string]types.AttributeValue, len(raw))
+		for name, v := range raw {
+			av, err := fromPlainJSONValue(v)
+			if err != nil {
+				return nil, fmt.Errorf("attribute %q: %w", name, err)
+			}
+			item[name] = av
+		}
+		return item, nil
+	}
+
+	var l exportLine
+	if err := json.Unmarshal(line, &l); err != nil {
+		return nil, err
+	}
+	if l.Item == nil {
+		return nil, fmt.Errorf("line has no Item")
+	}
+	return fromDynamoDBJSONItem(l.Item)
+}
+
+// toPlainJSONValue converts an attribute value into a value that encoding/json marshals as plain JSON.
+func toPlainJSONValue(av types.AttributeValue) any {
+	switch v := av.(type) {
+	case *types.AttributeValueMemberS:
+		return v.Value
+	case *types.AttributeValueMemberN:
+		return json.Number(v.Value)
+	case *types.AttributeValueMemberB:
+		return v.Value
+	case *types.AttributeValueMemberBOOL:
+		return v.Value
+	case *types.AttributeValueMemberSS:
+		return v.Value
+	case *types.AttributeValueMemberNS:
+		numbers := make([]json.Number, len(v.Value))
+		for n, s := range v.Value {
+			numbers[n] = json.Number(s)
+		}
+		return numbers
+	case *types.AttributeValueMemberBS:
+		return v.Value
+	case *types.AttributeValueMemberL:
+		list := make([]any, len(v.Value))
+		for n, elem := range v.Value {
+			list[n] = toPlainJSONValue(elem)
+		}
+		return list
+	case *types.AttributeValueMemberM:
+		m := make(map[string]any, len(v.Value))
+		for name, elem := range v.Value {
+			m[name] = toPlainJSONValue(elem)
+		}
+		return m
+	}
+	return nil
+}
+
+// fromPlainJSONValue converts a value decoded from plain JSON with UseNumber into an attribute value.
+func fromPlainJSONValue(v any) (types.AttributeValue, error) {
+	switch v := v.(type) {
+	case nil:
+		return &types.AttributeValueMemberNULL{Value: true}, nil
+	case string:
+		return &types.AttributeValueMemberS{Value: v}, nil
+	case json.Number:
+		return &types.AttributeValueMemberN{Value: v.String()}, nil
+	case bool:
+		return &types.AttributeValueMemberBOOL{Value: v}, nil
+	case []any:
+		list := make([]types.AttributeValue, len(v))
+		for n, elem := range v {
+			av, err := fromPlainJSONValue(elem)
+			if err != nil {
+				return nil, err
+			}
+			list[n] = av
+		}
+		return &types.AttributeValueMemberL{Value: list}, nil
+	case map[string]any:
+		m := make(map[string]types.AttributeValue, len(v))
+		for name, elem := range v {
+			av, err := fromPlainJSONValue(elem)
+			if err != nil {
+				return nil, err
+			}
+			m[name] = av
+		}
+		return &types.AttributeValueMemberM{Value: m}, nil
+	}
+	return nil, fmt.Errorf("unsupported json value %T", v)
+}
@@ -0,0 +1,103 @@
+package dygo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func exportFixture(fake *fakeDynamoDB, n int) {
+	for i := 0; i < n; i++ {
+		item := rawCachedItem(fmt.Sprintf("rm-%d", i), fmt.Sprintf("room %d", i))
+		item["floor"] = &types.AttributeValueMemberN{Value: "12345678901234567890"}
+		item["tags"] = &types.AttributeValueMemberSS{Value: []string{"a", "b"}}
+		fake.put("test-table-1", item)
+	}
+}
+
+func Test_export_import_round_trip(t *testing.T) {
+	source := newFakeDynamoDB()
+	exportFixture(source, 7)
+
+	var buf bytes.Buffer
+	n, err := newFakeClient(t, source).Export(context.Background(), &buf, ExportOptions{Segments: 3})
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	assert.Equal(t, 7, n)
+	assert.Equal(t, 7, strings.Count(buf.String(), "\n"))
+
+	target := newFakeDynamoDB()
+	var progress []int
+	result, err := newFakeClient(t, target).Import(context.Background(), &buf, ImportOptions{
+		Progress: func(r ImportResult) { progress = append(progress, r.Lines) },
+	})
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	assert.Equal(t, ImportResult{Lines: 7, Written: 7}, result)
+	assert.Equal(t, []int{7}, progress)
+
+	key := rawCachedItem("rm-3", "")
+	delete(key, "name")
+	assert.Equal(t, source.get("test-table-1", key), target.get("test-table-1", key))
+}
+
+func Test_export_plain_json(t *testing.T) {
+	source := newFakeDynamoDB()
+	exportFixture(source, 1)
+
+	var buf bytes.Buffer
+	_, err := newFakeClient(t, source).Export(context.Background(), &buf, ExportOptions{Format: FormatJSON})
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	assert.Contains(t, buf.String(), `"floor":12345678901234567890`)
+
+	item, err := decodeExportLine(buf.Bytes(), FormatJSON)
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "12345678901234567890"}, item["floor"])
+	assert.Equal(t, &types.AttributeValueMemberL{Value: []types.AttributeValue{
+		&types.AttributeValueMemberS{Value: "a"},
+		&types.AttributeValueMemberS{Value: "b"},
+	}}, item["tags"])
+}
+
+func Test_import_conflict_policies(t *testing.T) {
+	source := newFakeDynamoDB()
+	exportFixture(source, 4)
+	var buf bytes.Buffer
+	if _, err := newFakeClient(t, source).Export(context.Background(), &buf, ExportOptions{}); err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	lines := buf.String()
+
+	target := newFakeDynamoDB()
+	existing := rawCachedItem("rm-2", "existing")
+	target.put("test-table-1", existing)
+	c := newFakeClient(t, target)
+
+	result, err := c.Import(context.Background(), strings.NewReader(lines), ImportOptions{Conflict: ConflictFail, ChunkSize: 1})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "line 3")
+	assert.Equal(t, ImportResult{Lines: 2, Written: 2}, result)
+
+	// resume after the failed chunk, skipping existing items
+	result, err = c.Import(context.Background(), strings.NewReader(lines), ImportOptions{Conflict: ConflictSkip, Offset: result.Lines})
+	assert.NoError(t, err)
+	assert.Equal(t, ImportResult{Lines: 4, Written: 1, Skipped: 1}, result)
+	assert.Equal(t, 4, target.count("test-table-1"))
+	assert.Equal(t, existing, target.get("test-table-1", existing))
+
+	_, err = c.Import(context.Background(), strings.NewReader("{not json}\n"), ImportOptions{})
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, ErrNotFound))
+}
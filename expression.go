@@ -3,11 +3,23 @@ package dygo
 import (
 	"errors"
 	"log"
-	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
 )
 
+// buildExpression builds the expression of builder. Every expression of the package is built with it,
+// so the operands of contains conditions on non-string values are restored, see containsValue.
+func buildExpression(builder expression.Builder) (expression.Expression, error) {
+	expr, err := builder.Build()
+	if err != nil {
+		return expression.Expression{}, err
+	}
+	if err := restoreContainsValues(expr.Values()); err != nil {
+		return expression.Expression{}, err
+	}
+	return expr, nil
+}
+
 // getItemExpression returns the expression used to retrieve an item from the database.
 func (i *Item) getItemExpression() (*expression.Expression, error) {
 	if err := i.isGetItemValid(); err != nil {
@@ -15,14 +27,14 @@ func (i *Item) getItemExpression() (*expression.Expression, error) {
 	}
 	builder := expression.NewBuilder()
 	var expr expression.Expression
-	if i.projection != "" {
+	if len(i.projection) > 0 {
 		proj, err := projection(i.projection)
 		if err != nil {
 			return nil, err
 		}
 		builder = builder.WithProjection(*proj)
 
-		expr, err = builder.Build()
+		expr, err = buildExpression(builder)
 		if err != nil {
 			log.Fatalf("failed to build expression, %v", err)
 		}
@@ -39,7 +51,7 @@ func (i *Item) getQueryExpression() (*expression.Expression, error) {
 	}
 	builder := expression.NewBuilder().WithKeyCondition(*keyCondition)
 
-	if i.projection != "" {
+	if len(i.projection) > 0 {
 		proj, err := projection(i.projection)
 		if err != nil {
 			return nil, err
@@ -50,7 +62,7 @@ func (i *Item) getQueryExpression() (*expression.Expression, error) {
 	if i.filter.IsSet() {
 		builder = builder.WithFilter(i.filter)
 	}
-	expr, err := builder.Build()
+	expr, err := buildExpression(builder)
 	if err != nil {
 		return nil, err
 	}
@@ -62,7 +74,7 @@ func (i *Item) getQueryExpression() (*expression.Expression, error) {
 func (i *Item) getScanExpression() (*expression.Expression, error) {
 	builder := expression.NewBuilder()
 
-	if i.projection != "" {
+	if len(i.projection) > 0 {
 		proj, err := projection(i.projection)
 		if err != nil {
 			return nil, err
@@ -73,7 +85,7 @@ func (i *Item) getScanExpression() (*expression.Expression, error) {
 	if i.filter.IsSet() {
 		builder = builder.WithFilter(i.filter)
 	}
-	expr, err := builder.Build()
+	expr, err := buildExpression(builder)
 	if err != nil {
 		return nil, err
 	}
@@ -85,20 +97,21 @@ func getKeyCondition(i *Item) (*expression.KeyConditionBuilder, error) {
 	return &i.keyCondition, nil
 }
 
-// projection takes a string representing a comma-separated list of field names and returns a ProjectionBuilder
+// projection takes a list of attribute paths and returns a ProjectionBuilder
 // that can be used to build a projection expression for DynamoDB queries or scans.
-func projection(p string) (*expression.ProjectionBuilder, error) {
-	if p == "" {
-		return nil, errors.New("projection string is empty")
-	}
-	projection := strings.Split(p, ",")
-	if len(projection) == 0 {
+func projection(paths []string) (*expression.ProjectionBuilder, error) {
+	if len(paths) == 0 {
 		return nil, errors.New("no projection fields found")
 	}
-	proj := expression.NamesList(expression.Name(projection[0]))
-	for _, fieldName := range projection[1:] {
-		proj = proj.AddNames(expression.Name(fieldName))
+	names := make([]expression.NameBuilder, len(paths))
+	for n, path := range paths {
+		name, err := attributePath(path)
+		if err != nil {
+			return nil, err
+		}
+		names[n] = name
 	}
+	proj := expression.NamesList(names[0], names[1:]...)
 	return &proj, nil
 }
 
@@ -108,7 +121,7 @@ func (i *Item) deleteItemExpression() (expression.Expression, error) {
 	if i.c.sortKey != "" {
 		condition = condition.And(expression.AttributeExists(expression.Name(i.c.sortKey)))
 	}
-	expr, err := expression.NewBuilder().WithCondition(condition).Build()
+	expr, err := buildExpression(expression.NewBuilder().WithCondition(condition))
 	if err != nil {
 		return expression.Expression{}, err
 	}
@@ -121,27 +134,36 @@ func (i *Item) createItemExpression() (expression.Expression, error) {
 	if i.c.sortKey != "" {
 		condition = condition.And(expression.AttributeNotExists(expression.Name(i.c.sortKey)))
 	}
-	expr, err := expression.NewBuilder().WithCondition(condition).Build()
+	expr, err := buildExpression(expression.NewBuilder().WithCondition(condition))
 	if err != nil {
 		return expression.Expression{}, err
 	}
 	return expr, nil
 }
 
-// getUpdateItemExpression returns the expression for updating an item in DynamoDB.
+// getUpdateItemExpression returns the expression for updating an item in DynamoDB:
+// the SET of the attributes of a raw item or the update expression of the item, with its condition if any.
 func (i *Item) getUpdateItemExpression(index int) (*expression.Expression, error) {
 	if err := i.isUpdateItemValid(index); err != nil {
 		return nil, err
 	}
 
-	var updateBuilder expression.UpdateBuilder
-	for attrName, attrValue := range i.batchData.updateItems[index].updateItem {
-		updateBuilder = updateBuilder.Set(expression.Name(attrName), expression.Value(attrValue))
+	u := i.batchData.updateItems[index]
+	update := u.update
+	if u.updateItem != nil {
+		update = expression.UpdateBuilder{}
+		for attrName, attrValue := range u.updateItem {
+			update = update.Set(expression.Name(attrName), expression.Value(attrValue))
+		}
 	}
 
-	expr, err := expression.NewBuilder().WithUpdate(updateBuilder).Build()
+	builder := expression.NewBuilder().WithUpdate(update)
+	if u.condition.IsSet() {
+		builder = builder.WithCondition(u.condition)
+	}
+	expr, err := buildExpression(builder)
 	if err != nil {
-		log.Fatalf("failed to build expression, %v", err)
+		return nil, err
 	}
 	return &expr, nil
 }
@@ -151,7 +173,7 @@ func (i *Item) getConditionalUpdateExpression() (*expression.Expression, error)
 	if i.condition.IsSet() {
 		builder = builder.WithCondition(i.condition)
 	}
-	expr, err := builder.Build()
+	expr, err := buildExpression(builder)
 	if err != nil {
 		return nil, err
 	}
@@ -161,14 +183,14 @@ func (i *Item) getConditionalUpdateExpression() (*expression.Expression, error)
 func (i *Item) batchGetItemExpression() (*expression.Expression, error) {
 	builder := expression.NewBuilder()
 	var expr expression.Expression
-	if i.projection != "" {
+	if len(i.projection) > 0 {
 		proj, err := projection(i.projection)
 		if err != nil {
 			return nil, err
 		}
 		builder = builder.WithProjection(*proj)
 
-		expr, err = builder.Build()
+		expr, err = buildExpression(builder)
 		if err != nil {
 			log.Fatalf("failed to build expression, %v", err)
 		}
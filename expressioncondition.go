@@ -12,56 +12,56 @@ type ConditionFunc func(string) expression.ConditionBuilder
 // ConditionEqual returns a ConditionFunc that checks if the attribute value is equal to the specified value.
 func ConditionEqual(value any) ConditionFunc {
 	return func(keyName string) expression.ConditionBuilder {
-		return expression.Name(keyName).Equal(expression.Value(value))
+		return pathName(keyName).Equal(expression.Value(value))
 	}
 }
 
 // ConditionBeginsWith returns a ConditionFunc that checks item based on whether the attribute's value begins with the specified prefix.
 func ConditionBeginsWith(prefix any) ConditionFunc {
 	return func(keyName string) expression.ConditionBuilder {
-		return expression.Name(keyName).BeginsWith(prefix.(string))
+		return pathName(keyName).BeginsWith(prefix.(string))
 	}
 }
 
 // ConditionBetween returns a ConditionFunc that checks if the attribute value is between two specified values.
 func ConditionBetween(start, end any) ConditionFunc {
 	return func(keyName string) expression.ConditionBuilder {
-		return expression.Name(keyName).Between(expression.Value(start), expression.Value(end))
+		return pathName(keyName).Between(expression.Value(start), expression.Value(end))
 	}
 }
 
 // ConditionLessThan returns a ConditionFunc that checks if the attribute value is less than the specified value.
 func ConditionLessThan(value any) ConditionFunc {
 	return func(keyName string) expression.ConditionBuilder {
-		return expression.Name(keyName).LessThan(expression.Value(value))
+		return pathName(keyName).LessThan(expression.Value(value))
 	}
 }
 
 // ConditionLessThanOrEqual returns a ConditionFunc that checks if the attribute value is less than or equal to the specified value.
 func ConditionLessThanOrEqual(value any) ConditionFunc {
 	return func(keyName string) expression.ConditionBuilder {
-		return expression.Name(keyName).LessThanEqual(expression.Value(value))
+		return pathName(keyName).LessThanEqual(expression.Value(value))
 	}
 }
 
 // ConditionGreaterThan returns a ConditionFunc that checks if the attribute value is greater than the specified value.
 func ConditionGreaterThan(value any) ConditionFunc {
 	return func(keyName string) expression.ConditionBuilder {
-		return expression.Name(keyName).GreaterThan(expression.Value(value))
+		return pathName(keyName).GreaterThan(expression.Value(value))
 	}
 }
 
 // ConditionGreaterThanOrEqual returns a ConditionFunc that checks if the attribute value is greater than or equal to the specified value.
 func ConditionGreaterThanOrEqual(value any) ConditionFunc {
 	return func(keyName string) expression.ConditionBuilder {
-		return expression.Name(keyName).GreaterThanEqual(expression.Value(value))
+		return pathName(keyName).GreaterThanEqual(expression.Value(value))
 	}
 }
 
 // ConditionNotEqual returns a ConditionFunc that checks if the attribute value is not equal to the specified value.
 func ConditionNotEqual(value any) ConditionFunc {
 	return func(keyName string) expression.ConditionBuilder {
-		return expression.Name(keyName).NotEqual(expression.Value(value))
+		return pathName(keyName).NotEqual(expression.Value(value))
 	}
 }
 
@@ -78,22 +78,22 @@ func ConditionIn(value any) ConditionFunc {
 				operands[i] = expression.Value(strings.TrimSpace(v))
 			}
 			// Return the ConditionBuilder with the IN condition for the keyName.
-			return expression.Name(keyName).In(operands[0], operands[1:]...)
+			return pathName(keyName).In(operands[0], operands[1:]...)
 		}
-		return expression.Name(keyName).In(expression.Value(csv))
+		return pathName(keyName).In(expression.Value(csv))
 	}
 }
 
 // ConditionAttributeExists returns a ConditionFunc that checks if the specified attribute exists in the item.
 func ConditionAttributeExists() ConditionFunc {
 	return func(keyName string) expression.ConditionBuilder {
-		return expression.Name(keyName).AttributeExists()
+		return pathName(keyName).AttributeExists()
 	}
 }
 
 // ConditionAttributeNotExists returns a ConditionFunc that checks if the specified attribute does not exist in the item.
 func ConditionAttributeNotExists() ConditionFunc {
 	return func(keyName string) expression.ConditionBuilder {
-		return expression.Name(keyName).AttributeNotExists()
+		return pathName(keyName).AttributeNotExists()
 	}
 }
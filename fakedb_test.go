@@ -0,0 +1,309 @@
+package dygo
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// fakeDynamoDB is a minimal in-memory DynamoDB speaking the JSON wire protocol, used by unit tests
+// that exercise request handling without DynamoDB Local.
+// Condition expressions are only checked for attribute_exists and attribute_not_exists,
+// filter expressions are ignored.
+type fakeDynamoDB struct {
+	mu     sync.Mutex
+	keys   []string
+	tables map[string]map[string]map[string]types.AttributeValue
+	calls  map[string]int
+
+	// hook, if set, is called before every request. A non-zero status replaces the response.
+	hook func(op string, req map[string]json.RawMessage) (status int, resp any)
+}
+
+func newFakeDynamoDB() *fakeDynamoDB {
+	return &fakeDynamoDB{
+		keys:   []string{"_partition_key", "_sort_key"},
+		tables: make(map[string]map[string]map[string]types.AttributeValue),
+		calls:  make(map[string]int),
+	}
+}
+
+// newFakeClient returns a client of the test table talking to the fake.
+func newFakeClient(t *testing.T, fake *fakeDynamoDB, optFns ...func(*dynamodb.Options)) *Client {
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+	return &Client{
+		tableName:    "test-table-1",
+		partitionKey: "_partition_key",
+		sortKey:      "_sort_key",
+		keySeparator: "#",
+		client: dynamodb.New(dynamodb.Options{
+			Region:      "ap-northeast-1",
+			Credentials: aws.AnonymousCredentials{},
+			// EndpointResolverFromURL shares one endpoint between concurrent requests, which the race detector flags
+			EndpointResolver: dynamodb.EndpointResolverFunc(func(region string, options dynamodb.EndpointResolverOptions) (aws.Endpoint, error) {
+				return aws.Endpoint{URL: srv.URL}, nil
+			}),
+			Retryer: aws.NopRetryer{},
+		}, optFns...),
+	}
+}
+
+func (f *fakeDynamoDB) put(table string, item map[string]types.AttributeValue) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.table(table)[f.id(item)] = item
+}
+
+func (f *fakeDynamoDB) get(table string, key map[string]types.AttributeValue) map[string]types.AttributeValue {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.table(table)[f.id(key)]
+}
+
+func (f *fakeDynamoDB) count(table string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.table(table))
+}
+
+func (f *fakeDynamoDB) callCount(op string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[op]
+}
+
+func (f *fakeDynamoDB) table(name string) map[string]map[string]types.AttributeValue {
+	if f.tables[name] == nil {
+		f.tables[name] = make(map[string]map[string]types.AttributeValue)
+	}
+	return f.tables[name]
+}
+
+func (f *fakeDynamoDB) id(item map[string]types.AttributeValue) string {
+	key := make(map[string]types.AttributeValue, len(f.keys))
+	for _, name := range f.keys {
+		if v, ok := item[name]; ok {
+			key[name] = v
+		}
+	}
+	return cacheKey("", key)
+}
+
+func (f *fakeDynamoDB) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	op := strings.TrimPrefix(r.Header.Get("X-Amz-Target"), "DynamoDB_20120810.")
+	body, _ := io.ReadAll(r.Body)
+	req := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeFakeError(w, "SerializationException", err.Error())
+		return
+	}
+
+	f.mu.Lock()
+	f.calls[op]++
+	hook := f.hook
+	f.mu.Unlock()
+	if hook != nil {
+		if status, resp := hook(op, req); status != 0 {
+			w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(resp)
+			return
+		}
+	}
+
+	f.mu.Lock()
+	resp, code, msg := f.handle(op, req)
+	f.mu.Unlock()
+	if code != "" {
+		writeFakeError(w, code, msg)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// fakeError returns the body of a DynamoDB error response.
+func fakeError(code, msg string) map[string]string {
+	return map[string]string{"__type": "com.amazonaws.dynamodb.v20120810#" + code, "message": msg}
+}
+
+func writeFakeError(w http.ResponseWriter, code, msg string) {
+	w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(fakeError(code, msg))
+}
+
+func (f *fakeDynamoDB) handle(op string, req map[string]json.RawMessage) (any, string, string) {
+	var table string
+	json.Unmarshal(req["TableName"], &table)
+
+	switch op {
+	case "GetItem":
+		item := f.table(table)[f.id(decodeFakeItem(req["Key"]))]
+		if item == nil {
+			return map[string]any{}, "", ""
+		}
+		return map[string]any{"Item": encodeFakeItem(item)}, "", ""
+
+	case "PutItem":
+		item := decodeFakeItem(req["Item"])
+		old := f.table(table)[f.id(item)]
+		if !fakeConditionHolds(req, old) {
+			return nil, "ConditionalCheckFailedException", "The conditional request failed"
+		}
+		f.table(table)[f.id(item)] = item
+		return fakeReturnValues(req, old), "", ""
+
+	case "DeleteItem":
+		key := decodeFakeItem(req["Key"])
+		old := f.table(table)[f.id(key)]
+		if !fakeConditionHolds(req, old) {
+			return nil, "ConditionalCheckFailedException", "The conditional request failed"
+		}
+		delete(f.table(table), f.id(key))
+		return fakeReturnValues(req, old), "", ""
+
+	case "UpdateItem":
+		key := decodeFakeItem(req["Key"])
+		old := f.table(table)[f.id(key)]
+		if !fakeConditionHolds(req, old) {
+			return nil, "ConditionalCheckFailedException", "The conditional request failed"
+		}
+		item := copyItem(key)
+		for k, v := range old {
+			item[k] = v
+		}
+		f.applyUpdate(req, item)
+		f.table(table)[f.id(key)] = item
+		return map[string]any{}, "", ""
+
+	case "BatchGetItem":
+		var request map[string]struct {
+			Keys []json.RawMessage
+		}
+		json.Unmarshal(req["RequestItems"], &request)
+		responses := make(map[string][]any)
+		for name, keys := range request {
+			responses[name] = []any{}
+			for _, key := range keys.Keys {
+				if item := f.table(name)[f.id(decodeFakeItem(key))]; item != nil {
+					responses[name] = append(responses[name], encodeFakeItem(item))
+				}
+			}
+		}
+		return map[string]any{"Responses": responses, "UnprocessedKeys": map[string]any{}}, "", ""
+
+	case "BatchWriteItem":
+		var request map[string][]struct {
+			PutRequest    *struct{ Item json.RawMessage }
+			DeleteRequest *struct{ Key json.RawMessage }
+		}
+		json.Unmarshal(req["RequestItems"], &request)
+		for name, writes := range request {
+			for _, w := range writes {
+				switch {
+				case w.PutRequest != nil:
+					item := decodeFakeItem(w.PutRequest.Item)
+					f.table(name)[f.id(item)] = item
+				case w.DeleteRequest != nil:
+					delete(f.table(name), f.id(decodeFakeItem(w.DeleteRequest.Key)))
+				}
+			}
+		}
+		return map[string]any{"UnprocessedItems": map[string]any{}}, "", ""
+
+	case "Scan":
+		var segment, totalSegments int
+		json.Unmarshal(req["Segment"], &segment)
+		json.Unmarshal(req["TotalSegments"], &totalSegments)
+		ids := make([]string, 0, len(f.table(table)))
+		for id := range f.table(table) {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		items := []any{}
+		for n, id := range ids {
+			if totalSegments > 1 && n%totalSegments != segment {
+				continue
+			}
+			items = append(items, encodeFakeItem(f.table(table)[id]))
+		}
+		return map[string]any{"Items": items, "Count": len(items), "ScannedCount": len(items)}, "", ""
+	}
+	return nil, "UnknownOperationException", op
+}
+
+// applyUpdate applies the SET clauses of the update expression of the request to the item.
+func (f *fakeDynamoDB) applyUpdate(req map[string]json.RawMessage, item map[string]types.AttributeValue) {
+	var expr string
+	var names map[string]string
+	json.Unmarshal(req["UpdateExpression"], &expr)
+	json.Unmarshal(req["ExpressionAttributeNames"], &names)
+	values := decodeFakeItem(req["ExpressionAttributeValues"])
+	expr = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(expr), "SET"))
+	for _, clause := range strings.Split(expr, ",") {
+		parts := strings.SplitN(clause, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		item[names[strings.TrimSpace(parts[0])]] = values[strings.TrimSpace(parts[1])]
+	}
+}
+
+// fakeConditionHolds checks the attribute_exists and attribute_not_exists functions of the condition expression.
+func fakeConditionHolds(req map[string]json.RawMessage, old map[string]types.AttributeValue) bool {
+	var cond string
+	json.Unmarshal(req["ConditionExpression"], &cond)
+	switch {
+	case strings.Contains(cond, "attribute_not_exists"):
+		return old == nil
+	case strings.Contains(cond, "attribute_exists"):
+		return old != nil
+	}
+	return true
+}
+
+func fakeReturnValues(req map[string]json.RawMessage, old map[string]types.AttributeValue) any {
+	var rv string
+	json.Unmarshal(req["ReturnValues"], &rv)
+	if rv != "ALL_OLD" || old == nil {
+		return map[string]any{}
+	}
+	return map[string]any{"Attributes": encodeFakeItem(old)}
+}
+
+func decodeFakeItem(data json.RawMessage) map[string]types.AttributeValue {
+	var raw map[string]map[string]json.RawMessage
+	json.Unmarshal(data, &raw)
+	item := make(map[string]types.AttributeValue, len(raw))
+	for name, v := range raw {
+		av, err := fromDynamoDBJSONValue(v)
+		if err != nil {
+			panic(err)
+		}
+		item[name] = av
+	}
+	return item
+}
+
+func encodeFakeItem(item map[string]types.AttributeValue) map[string]any {
+	out := make(map[string]any, len(item))
+	for name, v := range item {
+		value, err := toDynamoDBJSONValue(v)
+		if err != nil {
+			panic(err)
+		}
+		out[name] = value
+	}
+	return out
+}
@@ -12,14 +12,14 @@ type FilterFunc func(string) expression.ConditionBuilder
 // KeyEqual returns a FilterFunc that filters based on the equality of a key's value.
 func KeyEqual(value any) FilterFunc {
 	return func(keyName string) expression.ConditionBuilder {
-		return expression.Name(keyName).Equal(expression.Value(value))
+		return pathName(keyName).Equal(expression.Value(value))
 	}
 }
 
 // KeyBeginsWith returns a FilterFunc that filters items based on whether the key begins with the specified prefix.
 func KeyBeginsWith(prefix any) FilterFunc {
 	return func(keyName string) expression.ConditionBuilder {
-		return expression.Name(keyName).BeginsWith(prefix.(string))
+		return pathName(keyName).BeginsWith(prefix.(string))
 	}
 }
 
@@ -28,35 +28,35 @@ func KeyBeginsWith(prefix any) FilterFunc {
 // The start and end values can be of any type.
 func KeyBetween(start, end any) FilterFunc {
 	return func(keyName string) expression.ConditionBuilder {
-		return expression.Name(keyName).Between(expression.Value(start), expression.Value(end))
+		return pathName(keyName).Between(expression.Value(start), expression.Value(end))
 	}
 }
 
 // KeyLessThan returns a FilterFunc that filters items based on whether the value of the specified key is less than the given value.
 func KeyLessThan(value any) FilterFunc {
 	return func(keyName string) expression.ConditionBuilder {
-		return expression.Name(keyName).LessThan(expression.Value(value))
+		return pathName(keyName).LessThan(expression.Value(value))
 	}
 }
 
 // KeyLessThanOrEqual returns a FilterFunc that filters items where the value of the specified key is less than or equal to the given value.
 func KeyLessThanOrEqual(value any) FilterFunc {
 	return func(keyName string) expression.ConditionBuilder {
-		return expression.Name(keyName).LessThanEqual(expression.Value(value))
+		return pathName(keyName).LessThanEqual(expression.Value(value))
 	}
 }
 
 // KeyGreaterThan returns a FilterFunc that filters items based on the specified key being greater than the given value.
 func KeyGreaterThan(value any) FilterFunc {
 	return func(keyName string) expression.ConditionBuilder {
-		return expression.Name(keyName).GreaterThan(expression.Value(value))
+		return pathName(keyName).GreaterThan(expression.Value(value))
 	}
 }
 
 // KeyGreaterThanOrEqual returns a FilterFunc that filters items where the value of the specified key is greater than or equal to the given value.
 func KeyGreaterThanOrEqual(value any) FilterFunc {
 	return func(keyName string) expression.ConditionBuilder {
-		return expression.Name(keyName).GreaterThanEqual(expression.Value(value))
+		return pathName(keyName).GreaterThanEqual(expression.Value(value))
 	}
 }
 
@@ -64,35 +64,53 @@ func KeyGreaterThanOrEqual(value any) FilterFunc {
 // that is not equal to the specified value.
 func KeyNotEqual(value any) FilterFunc {
 	return func(keyName string) expression.ConditionBuilder {
-		return expression.Name(keyName).NotEqual(expression.Value(value))
+		return pathName(keyName).NotEqual(expression.Value(value))
 	}
 }
 
 // KeyContains returns a FilterFunc that checks if the given value is contained in the key's value.
+// A string value matches a substring of a string attribute or an element of a string set or list;
+// any other value matches an element of a set or list of its type, e.g. an int matches an element of a number set.
 func KeyContains(value any) FilterFunc {
 	return func(keyName string) expression.ConditionBuilder {
-		return expression.Name(keyName).Contains(getStringValue(value))
+		return containsCondition(keyName, value)
 	}
 }
 
 // KeyNotContains returns a FilterFunc that filters out items where the value of the specified key does not contain the given value.
+// The value is matched as in KeyContains.
 func KeyNotContains(value any) FilterFunc {
 	return func(keyName string) expression.ConditionBuilder {
-		return expression.Name(keyName).Contains(getStringValue(value)).Not()
+		return containsCondition(keyName, value).Not()
+	}
+}
+
+// KeySizeGreaterThan returns a FilterFunc that checks if the size of the key's value is greater than the given size:
+// the length of a string or binary, or the number of elements of a set, list or map.
+func KeySizeGreaterThan(size int) FilterFunc {
+	return func(keyName string) expression.ConditionBuilder {
+		return pathName(keyName).Size().GreaterThan(expression.Value(size))
+	}
+}
+
+// KeyAttributeType returns a FilterFunc that checks if the key's value is of the given type.
+func KeyAttributeType(attributeType AttributeType) FilterFunc {
+	return func(keyName string) expression.ConditionBuilder {
+		return pathName(keyName).AttributeType(attributeType)
 	}
 }
 
 // KeyNotNull returns a FilterFunc that checks if the specified key is not null.
 func KeyNotNull() FilterFunc {
 	return func(keyName string) expression.ConditionBuilder {
-		return expression.Name(keyName).AttributeExists()
+		return pathName(keyName).AttributeExists()
 	}
 }
 
 // KeyNull returns a FilterFunc that checks if the specified key does not exist in the DynamoDB item.
 func KeyNull() FilterFunc {
 	return func(keyName string) expression.ConditionBuilder {
-		return expression.Name(keyName).AttributeNotExists()
+		return pathName(keyName).AttributeNotExists()
 	}
 }
 
@@ -109,8 +127,8 @@ func KeyIn(value any) FilterFunc {
 				operands[i] = expression.Value(strings.TrimSpace(v))
 			}
 			// Return the ConditionBuilder with the IN condition for the keyName.
-			return expression.Name(keyName).In(operands[0], operands[1:]...)
+			return pathName(keyName).In(operands[0], operands[1:]...)
 		}
-		return expression.Name(keyName).In(expression.Value(csv))
+		return pathName(keyName).In(expression.Value(csv))
 	}
 }
@@ -0,0 +1,449 @@
+package dygo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+)
+
+// FilterSyntaxError is returned by ParseFilter when the filter string is invalid.
+// Column is the 1-based position, in bytes, of the offending token in the filter string.
+type FilterSyntaxError struct {
+	Column  int
+	Message string
+}
+
+// Error returns the error message with the position of the error.
+func (e *FilterSyntaxError) Error() string {
+	return fmt.Sprintf("filter syntax error at column %d: %s", e.Column, e.Message)
+}
+
+// ParseFilter parses a filter string into the condition builder the FilterFuncs produce.
+// If allowed attribute names are given, any other attribute in the filter string is rejected.
+// Errors are returned as *FilterSyntaxError.
+//
+// The grammar is:
+//
+//	filter     = term { OR term }
+//	term       = factor { AND factor }
+//	factor     = NOT factor | "(" filter ")" | predicate
+//	predicate  = attribute ( "=" | "<>" | "!=" | "<" | "<=" | ">" | ">=" ) value
+//	           | attribute ( begins_with | contains | not_contains ) value
+//	           | attribute BETWEEN value AND value
+//	           | attribute IN "(" value { "," value } ")"
+//	           | attribute ( exists | not_exists )
+//	value      = 'string' | "string" | number | true | false
+//
+// Attributes are attribute paths, such as address.city or tags[0], see EscapeName.
+// Keywords are case-insensitive.
+//
+// Example:
+//
+//	filter, err := dygo.ParseFilter(
+//		"physical_name begins_with 'room' AND (version > 3 OR _entity_type IN ('room','hotel'))",
+//		"physical_name", "version", "_entity_type",
+//	)
+func ParseFilter(input string, allowed ...string) (expression.ConditionBuilder, error) {
+	return parseFilter(input, allowed, func(attributeName string, value any) FilterFunc {
+		return KeyEqual(value)
+	}, func(attributeName string, f FilterFunc) (expression.ConditionBuilder, error) {
+		return f(attributeName), nil
+	})
+}
+
+// FilterString sets the filter of the item from a filter string, see ParseFilter for the syntax.
+// Equality predicates on attributes with a blind index are rewritten as with Filter.
+//
+// Example:
+//
+//	err = db.
+//		GSI("gsi-name", "room", Equal("current")).
+//		FilterString("physical_name begins_with 'lib' AND capacity >= 20", "physical_name", "capacity").
+//		Query(context.Background()).
+//		Unmarshal(&data, []string{"room"}).
+//		Run()
+func (i *Item) FilterString(input string, allowed ...string) *Item {
+	condition, err := parseFilter(input, allowed, i.c.equalFilter, i.c.filterCondition)
+	if err != nil {
+		if i.err == nil {
+			i.err = validationError().method("Filter").wrap(err)
+		}
+		return i
+	}
+	i.filter = condition
+	return i
+}
+
+type filterTokenKind int
+
+const (
+	tokenEOF filterTokenKind = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenOperator
+	tokenLeftParen
+	tokenRightParen
+	tokenComma
+)
+
+type filterToken struct {
+	kind  filterTokenKind
+	text  string
+	value any
+	pos   int
+}
+
+// filterParser is a recursive descent parser over the tokens of a filter string.
+type filterParser struct {
+	tokens  []filterToken
+	next    int
+	allowed map[string]bool
+	equal   func(string, any) FilterFunc
+	build   func(string, FilterFunc) (expression.ConditionBuilder, error)
+}
+
+func parseFilter(input string, allowed []string, equal func(string, any) FilterFunc, build func(string, FilterFunc) (expression.ConditionBuilder, error)) (expression.ConditionBuilder, error) {
+	tokens, err := tokenizeFilter(input)
+	if err != nil {
+		return expression.ConditionBuilder{}, err
+	}
+	p := &filterParser{tokens: tokens, equal: equal, build: build}
+	if len(allowed) > 0 {
+		p.allowed = make(map[string]bool, len(allowed))
+		for _, name := range allowed {
+			p.allowed[name] = true
+		}
+	}
+	if p.peek().kind == tokenEOF {
+		return expression.ConditionBuilder{}, p.errorf(p.peek(), "empty filter")
+	}
+	condition, err := p.parseOr()
+	if err != nil {
+		return expression.ConditionBuilder{}, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return expression.ConditionBuilder{}, p.errorf(tok, "unexpected %q, expected AND, OR or end of filter", tok.text)
+	}
+	return condition, nil
+}
+
+func (p *filterParser) peek() filterToken {
+	return p.tokens[p.next]
+}
+
+func (p *filterParser) advance() filterToken {
+	tok := p.tokens[p.next]
+	if tok.kind != tokenEOF {
+		p.next++
+	}
+	return tok
+}
+
+// keyword reports whether the next token is the given keyword and consumes it if so.
+func (p *filterParser) keyword(word string) bool {
+	tok := p.peek()
+	if tok.kind == tokenIdent && strings.EqualFold(tok.text, word) {
+		p.next++
+		return true
+	}
+	return false
+}
+
+func (p *filterParser) errorf(tok filterToken, format string, args ...any) error {
+	return &FilterSyntaxError{Column: tok.pos + 1, Message: fmt.Sprintf(format, args...)}
+}
+
+func (p *filterParser) parseOr() (expression.ConditionBuilder, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return left, err
+	}
+	for p.keyword("OR") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return right, err
+		}
+		left = expression.Or(left, right)
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (expression.ConditionBuilder, error) {
+	left, err := p.parseFactor()
+	if err != nil {
+		return left, err
+	}
+	for p.keyword("AND") {
+		right, err := p.parseFactor()
+		if err != nil {
+			return right, err
+		}
+		left = expression.And(left, right)
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseFactor() (expression.ConditionBuilder, error) {
+	if p.keyword("NOT") {
+		condition, err := p.parseFactor()
+		if err != nil {
+			return condition, err
+		}
+		return expression.Not(condition), nil
+	}
+	if tok := p.peek(); tok.kind == tokenLeftParen {
+		p.advance()
+		condition, err := p.parseOr()
+		if err != nil {
+			return condition, err
+		}
+		if tok := p.advance(); tok.kind != tokenRightParen {
+			return condition, p.errorf(tok, "expected \")\"")
+		}
+		return condition, nil
+	}
+	return p.parsePredicate()
+}
+
+func (p *filterParser) parsePredicate() (expression.ConditionBuilder, error) {
+	attr := p.advance()
+	if attr.kind != tokenIdent {
+		return expression.ConditionBuilder{}, p.errorf(attr, "expected attribute name")
+	}
+	if _, err := parsePath(attr.text); err != nil {
+		return expression.ConditionBuilder{}, p.errorf(attr, "%s", err.Error())
+	}
+	if p.allowed != nil && !p.allowed[attr.text] {
+		return expression.ConditionBuilder{}, p.errorf(attr, "attribute %q is not allowed", attr.text)
+	}
+
+	f, err := p.parseOperator(attr.text)
+	if err != nil {
+		return expression.ConditionBuilder{}, err
+	}
+	condition, err := p.build(attr.text, f)
+	if err != nil {
+		return condition, p.errorf(attr, "%s", err.Error())
+	}
+	return condition, nil
+}
+
+// parseOperator parses the operator and operands of a predicate on the attribute into a FilterFunc.
+func (p *filterParser) parseOperator(attributeName string) (FilterFunc, error) {
+	op := p.advance()
+	switch op.kind {
+	case tokenOperator:
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		switch op.text {
+		case "=":
+			return p.equal(attributeName, value), nil
+		case "<>", "!=":
+			return KeyNotEqual(value), nil
+		case "<":
+			return KeyLessThan(value), nil
+		case "<=":
+			return KeyLessThanOrEqual(value), nil
+		case ">":
+			return KeyGreaterThan(value), nil
+		case ">=":
+			return KeyGreaterThanOrEqual(value), nil
+		}
+	case tokenIdent:
+		switch strings.ToLower(op.text) {
+		case "begins_with":
+			tok := p.peek()
+			value, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := value.(string); !ok {
+				return nil, p.errorf(tok, "begins_with expects a string")
+			}
+			return KeyBeginsWith(value), nil
+		case "contains", "not_contains":
+			value, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			if strings.EqualFold(op.text, "contains") {
+				return KeyContains(value), nil
+			}
+			return KeyNotContains(value), nil
+		case "between":
+			start, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			if !p.keyword("AND") {
+				return nil, p.errorf(p.peek(), "expected AND in BETWEEN")
+			}
+			end, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			return KeyBetween(start, end), nil
+		case "in":
+			return p.parseIn()
+		case "exists":
+			return KeyNotNull(), nil
+		case "not_exists":
+			return KeyNull(), nil
+		}
+	}
+	return nil, p.errorf(op, "expected operator, got %q", op.text)
+}
+
+func (p *filterParser) parseIn() (FilterFunc, error) {
+	if tok := p.advance(); tok.kind != tokenLeftParen {
+		return nil, p.errorf(tok, "expected \"(\" after IN")
+	}
+	var operands []expression.OperandBuilder
+	for {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, expression.Value(value))
+		tok := p.advance()
+		if tok.kind == tokenRightParen {
+			break
+		}
+		if tok.kind != tokenComma {
+			return nil, p.errorf(tok, "expected \",\" or \")\" in IN list")
+		}
+	}
+	return func(keyName string) expression.ConditionBuilder {
+		return pathName(keyName).In(operands[0], operands[1:]...)
+	}, nil
+}
+
+func (p *filterParser) parseValue() (any, error) {
+	tok := p.advance()
+	switch tok.kind {
+	case tokenString, tokenNumber:
+		return tok.value, nil
+	case tokenIdent:
+		switch strings.ToLower(tok.text) {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+	}
+	if tok.kind == tokenEOF {
+		return nil, p.errorf(tok, "expected value, got end of filter")
+	}
+	return nil, p.errorf(tok, "expected value, got %q", tok.text)
+}
+
+// tokenizeFilter splits a filter string into tokens, always ending with a tokenEOF.
+func tokenizeFilter(input string) ([]filterToken, error) {
+	var tokens []filterToken
+	pos := 0
+	for pos < len(input) {
+		c := input[pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			pos++
+		case c == '(':
+			tokens = append(tokens, filterToken{kind: tokenLeftParen, text: "(", pos: pos})
+			pos++
+		case c == ')':
+			tokens = append(tokens, filterToken{kind: tokenRightParen, text: ")", pos: pos})
+			pos++
+		case c == ',':
+			tokens = append(tokens, filterToken{kind: tokenComma, text: ",", pos: pos})
+			pos++
+		case c == '=' || c == '<' || c == '>' || c == '!':
+			end := pos + 1
+			if end < len(input) && (input[end] == '=' || (c == '<' && input[end] == '>')) {
+				end++
+			}
+			text := input[pos:end]
+			if text == "!" {
+				return nil, &FilterSyntaxError{Column: pos + 1, Message: `unexpected "!", expected "!="`}
+			}
+			tokens = append(tokens, filterToken{kind: tokenOperator, text: text, pos: pos})
+			pos = end
+		case c == '\'' || c == '"':
+			value, end, err := scanFilterString(input, pos)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, filterToken{kind: tokenString, text: input[pos:end], value: value, pos: pos})
+			pos = end
+		case c == '-' || c == '.' || (c >= '0' && c <= '9'):
+			end := pos + 1
+			for end < len(input) && strings.IndexByte("0123456789.eE+-", input[end]) >= 0 {
+				end++
+			}
+			value, err := parseFilterNumber(input[pos:end])
+			if err != nil {
+				return nil, &FilterSyntaxError{Column: pos + 1, Message: fmt.Sprintf("invalid number %q", input[pos:end])}
+			}
+			tokens = append(tokens, filterToken{kind: tokenNumber, text: input[pos:end], value: value, pos: pos})
+			pos = end
+		case isFilterIdentRune(rune(c), true):
+			// attribute paths also hold dots, list indexes and backslash escapes, checked by the parser
+			end := pos + 1
+			for end < len(input) {
+				if input[end] == '\\' && end+1 < len(input) {
+					end += 2
+					continue
+				}
+				if !isFilterIdentRune(rune(input[end]), false) && strings.IndexByte(".[]", input[end]) < 0 {
+					break
+				}
+				end++
+			}
+			tokens = append(tokens, filterToken{kind: tokenIdent, text: input[pos:end], pos: pos})
+			pos = end
+		default:
+			return nil, &FilterSyntaxError{Column: pos + 1, Message: fmt.Sprintf("unexpected character %q", c)}
+		}
+	}
+	return append(tokens, filterToken{kind: tokenEOF, pos: len(input)}), nil
+}
+
+func isFilterIdentRune(r rune, first bool) bool {
+	if r == '_' || (r < unicode.MaxASCII && unicode.IsLetter(r)) {
+		return true
+	}
+	return !first && (r == '-' || unicode.IsDigit(r))
+}
+
+// scanFilterString reads the quoted string starting at pos, where a backslash escapes the next character.
+// It returns the unquoted value and the position after the closing quote.
+func scanFilterString(input string, pos int) (string, int, error) {
+	quote := input[pos]
+	var b strings.Builder
+	for n := pos + 1; n < len(input); n++ {
+		switch input[n] {
+		case '\\':
+			if n+1 < len(input) {
+				n++
+				b.WriteByte(input[n])
+			}
+		case quote:
+			return b.String(), n + 1, nil
+		default:
+			b.WriteByte(input[n])
+		}
+	}
+	return "", 0, &FilterSyntaxError{Column: pos + 1, Message: "unterminated string"}
+}
+
+// parseFilterNumber parses an integer as int64 and any other number as float64.
+func parseFilterNumber(s string) (any, error) {
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n, nil
+	}
+	return strconv.ParseFloat(s, 64)
+}
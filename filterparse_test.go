@@ -0,0 +1,106 @@
+package dygo
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/stretchr/testify/assert"
+)
+
+func buildFilter(t *testing.T, condition expression.ConditionBuilder) expression.Expression {
+	expr, err := expression.NewBuilder().WithFilter(condition).Build()
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	return expr
+}
+
+func Test_parse_filter(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  expression.ConditionBuilder
+	}{
+		{
+			name:  "grouping",
+			input: "physical_name begins_with 'room' AND (version > 3 OR _entity_type IN ('room','hotel'))",
+			want: expression.And(
+				KeyBeginsWith("room")("physical_name"),
+				expression.Or(
+					KeyGreaterThan(int64(3))("version"),
+					expression.Name("_entity_type").In(expression.Value("room"), expression.Value("hotel")),
+				),
+			),
+		},
+		{
+			name:  "precedence",
+			input: "a = 1 OR b = 2 and not c exists",
+			want: expression.Or(
+				KeyEqual(int64(1))("a"),
+				expression.And(KeyEqual(int64(2))("b"), expression.Not(KeyNotNull()("c"))),
+			),
+		},
+		{
+			name:  "between and escapes",
+			input: `price BETWEEN 1.5 AND -2 AND name <> "it\"s" AND flag = true`,
+			want: expression.And(
+				expression.And(KeyBetween(1.5, int64(-2))("price"), KeyNotEqual(`it"s`)("name")),
+				KeyEqual(true)("flag"),
+			),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFilter(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error : %v", err)
+			}
+			want := buildFilter(t, tt.want)
+			expr := buildFilter(t, got)
+			assert.Equal(t, *want.Filter(), *expr.Filter())
+			assert.Equal(t, want.Names(), expr.Names())
+			assert.Equal(t, want.Values(), expr.Values())
+		})
+	}
+}
+
+func Test_parse_filter_errors(t *testing.T) {
+	tests := []struct {
+		input   string
+		allowed []string
+		column  int
+		message string
+	}{
+		{input: "", column: 1, message: "empty filter"},
+		{input: "name = 'abc", column: 8, message: "unterminated string"},
+		{input: "name = ", column: 8, message: "expected value"},
+		{input: "name like 'a'", column: 6, message: "expected operator"},
+		{input: "(a = 1", column: 7, message: `expected ")"`},
+		{input: "a = 1 b = 2", column: 7, message: "unexpected"},
+		{input: "a begins_with 3", column: 15, message: "expects a string"},
+		{input: "a = 1 AND secret = 2", allowed: []string{"a"}, column: 11, message: `attribute "secret" is not allowed`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := ParseFilter(tt.input, tt.allowed...)
+			var syntaxErr *FilterSyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("expected a FilterSyntaxError, got %v", err)
+			}
+			assert.Equal(t, tt.column, syntaxErr.Column)
+			assert.Contains(t, syntaxErr.Message, tt.message)
+		})
+	}
+}
+
+func Test_filter_string(t *testing.T) {
+	c := &Client{tableName: "test-table-1", partitionKey: "_partition_key"}
+	i := c.InitScan().FilterString("capacity >= 20", "capacity")
+	assert.NoError(t, i.err)
+	assert.True(t, i.filter.IsSet())
+
+	i = c.InitScan().FilterString("capacity >=", "capacity")
+	var syntaxErr *FilterSyntaxError
+	assert.True(t, errors.As(i.err, &syntaxErr))
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 )
 
 const opGet = "Get"
@@ -25,38 +26,75 @@ func (i *Item) GetItem(ctx context.Context, out interface{}) error {
 		return i.err
 	}
 
-	expr, err := i.getItemExpression()
+	item, err := i.getItem(ctx)
 	if err != nil {
-		return dynamoError().method(opGet).message(err.Error())
+		return err
 	}
 
-	input := dynamodb.GetItemInput{
-		Key:       i.key,
-		TableName: aws.String(i.c.tableName),
+	if err := attributevalue.UnmarshalMap(item, &out); err != nil {
+		return dynamoError().method(opGet).wrap(err)
 	}
 
-	if expr.Projection() != nil {
-		input.ProjectionExpression = expr.Projection()
-		input.ExpressionAttributeNames = expr.Names()
+	return nil
+}
+
+// MustGetItem retrieves an item from DynamoDB like GetItem, but returns an error matching ErrNotFound
+// if there is no item with the key, instead of leaving 'out' unchanged.
+//
+// Example:
+//
+//	d := dataItem{}
+//	err = db.
+//		PK(PK).
+//		SK(Equal(SK)).
+//		MustGetItem(context.Background(), &d)
+//	if errors.Is(err, ErrNotFound) {
+//		...
+//	}
+func (i *Item) MustGetItem(ctx context.Context, out interface{}) error {
+	found, err := i.GetItemOK(ctx, out)
+	if err != nil {
+		return err
 	}
+	if !found {
+		return i.notFoundError(opGet)
+	}
+	return nil
+}
 
-	output, err := i.c.client.GetItem(ctx, &input)
+// GetItemOK retrieves an item from DynamoDB like GetItem, and reports whether there is an item with the key.
+// 'out' is left unchanged if there is none.
+//
+// Example:
+//
+//	d := dataItem{}
+//	found, err := db.
+//		PK(PK).
+//		SK(Equal(SK)).
+//		GetItemOK(context.Background(), &d)
+func (i *Item) GetItemOK(ctx context.Context, out interface{}) (bool, error) {
+	if i.err != nil {
+		return false, i.err
+	}
+
+	item, err := i.getItem(ctx)
 	if err != nil {
-		if err := getDynamoDBError(opGet, err); err != nil {
-			return dynamoError().method(opGet).message(err.Error())
-		}
-		return dynamoError().method(opGet).message(err.Error())
+		return false, err
+	}
+	if item == nil {
+		return false, nil
 	}
 
-	if err := attributevalue.UnmarshalMap(output.Item, &out); err != nil {
-		return dynamoError().method(opGet).message(err.Error())
+	if err := attributevalue.UnmarshalMap(item, &out); err != nil {
+		return false, dynamoError().method(opGet).wrap(err)
 	}
 
-	return nil
+	return true, nil
 }
 
 // GetAuthorizedItem retrieves an authorized item from DynamoDB based on the provided key.
 // It performs authorization checks on the retrieved item with user defined Authorize() function before returning it.
+// Authorization also runs for items served from the cache configured with WithCache.
 // If there is an error during the retrieval or authorization process, it returns the corresponding error.
 //
 // Example:
@@ -74,9 +112,37 @@ func (i *Item) GetAuthorizedItem(ctx context.Context, out Out) error {
 		return i.err
 	}
 
+	item, err := i.getItem(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := attributevalue.UnmarshalMap(item, &out); err != nil {
+		return err
+	}
+
+	err = out.Authorize(ctx)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// getItem retrieves the decoded item with the key of the Item, reading through the cache if one is configured.
+// It returns a nil item if there is no item with the key.
+func (i *Item) getItem(ctx context.Context) (map[string]types.AttributeValue, error) {
 	expr, err := i.getItemExpression()
 	if err != nil {
-		return dynamoError().method(opGet).message(err.Error())
+		return nil, dynamoError().method(opGet).wrap(err)
+	}
+
+	var generation uint64
+	if i.useCache() {
+		if item, ok := i.c.cachedItem(i.c.tableName, i.key); ok {
+			return item, nil
+		}
+		generation = i.c.beginCacheFill()
+		defer i.c.endCacheFill()
 	}
 
 	input := dynamodb.GetItemInput{
@@ -91,16 +157,15 @@ func (i *Item) GetAuthorizedItem(ctx context.Context, out Out) error {
 
 	output, err := i.c.client.GetItem(ctx, &input)
 	if err != nil {
-		return getDynamoDBError(opGet, err)
+		return nil, i.opError(opGet, err)
 	}
 
-	if err := attributevalue.UnmarshalMap(output.Item, &out); err != nil {
-		return err
+	if err := i.c.decodeItem(ctx, output.Item); err != nil {
+		return nil, dynamoError().method(opGet).wrap(err)
 	}
 
-	err = out.Authorize(ctx)
-	if err != nil {
-		return err
+	if i.useCache() {
+		i.c.cacheItem(generation, i.c.tableName, i.key, output.Item)
 	}
-	return nil
+	return output.Item, nil
 }
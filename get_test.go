@@ -2,7 +2,11 @@ package dygo
 
 import (
 	"context"
+	"errors"
 	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
 )
 
 func Test_get_item_happy_path(t *testing.T) {
@@ -151,3 +155,34 @@ func Test_get_item_happy_path_pkskUnique(t *testing.T) {
 	// remove item
 	removeItem(t, PK, SK)
 }
+
+func Test_get_item_not_found(t *testing.T) {
+	fake := newFakeDynamoDB()
+	db := newFakeClient(t, fake)
+	pk := newPK("room")
+	fake.put("test-table-1", map[string]types.AttributeValue{
+		"_partition_key": &types.AttributeValueMemberS{Value: pk},
+		"_sort_key":      &types.AttributeValueMemberS{Value: "current"},
+		"logical_name":   &types.AttributeValueMemberS{Value: "hall"},
+	})
+
+	d := dataItem{}
+	found, err := db.PK(pk).SK(Equal("current")).GetItemOK(context.Background(), &d)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "hall", d.LogicalName)
+
+	d = dataItem{}
+	found, err = db.PK(pk).SK(Equal("previous")).GetItemOK(context.Background(), &d)
+	assert.NoError(t, err)
+	assert.False(t, found)
+	assert.Equal(t, dataItem{}, d)
+
+	err = db.PK(pk).SK(Equal("previous")).MustGetItem(context.Background(), &d)
+	assert.True(t, errors.Is(err, ErrNotFound))
+	var opErr *OpError
+	if assert.True(t, errors.As(err, &opErr)) {
+		assert.Equal(t, &types.AttributeValueMemberS{Value: "previous"}, opErr.Key["_sort_key"])
+	}
+	assert.NoError(t, db.PK(pk).SK(Equal("current")).MustGetItem(context.Background(), &d))
+}
@@ -0,0 +1,214 @@
+package dygo
+
+import (
+	"context"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"golang.org/x/sync/errgroup"
+)
+
+const opGetBatch = "GetBatch"
+
+// GetBatch collects the keys of items of the tables of any number of clients and fetches them together
+// with BatchGetItem, returning the items in the order of the keys and the keys without an item.
+// Each table is read with the client its keys were created with, so items are decoded and cached per table.
+// Projections set on the keys are ignored, a GetBatch always fetches complete items.
+//
+// Example:
+//
+//	result, err := dygo.NewGetBatch().
+//		Get(rooms.PK(PK).SK(Equal(SK))).
+//		Get(bookings.PK(bookingID).SK(Equal(SK))).
+//		ConsistentRead("bookings").
+//		Fetch(context.Background(), 10)
+//	for n, item := range result.Items {
+//		if item == nil {
+//			// the n-th key has no item
+//		}
+//	}
+type GetBatch struct {
+	keys       []getRequest
+	consistent map[string]bool
+	err        error
+}
+
+// getRequest is a key of a GetBatch, with the client of its table.
+type getRequest struct {
+	c   *Client
+	key BatchKey
+}
+
+// getChunk is a set of keys of a GetBatch fetched with one BatchGetItem call.
+type getChunk struct {
+	tables   batchTables
+	requests map[string]types.KeysAndAttributes
+	count    int
+}
+
+// GetBatchResult is the outcome of GetBatch.Fetch.
+type GetBatchResult struct {
+	// Items are the items of the keys, in the order the keys were added; the item of a key without an item is nil.
+	Items []map[string]types.AttributeValue
+	// Missing are the keys without an item, in the order they were added.
+	Missing []BatchKey
+
+	index map[string]int
+}
+
+// NewGetBatch returns an empty GetBatch.
+func NewGetBatch() *GetBatch {
+	return &GetBatch{consistent: make(map[string]bool)}
+}
+
+// Get adds the key set on key, created with PK of the client of its table.
+// A key can be added more than once, it is fetched once and its item is returned at each of its positions.
+func (b *GetBatch) Get(key *Item) *GetBatch {
+	if b.err != nil {
+		return b
+	}
+	if key.err != nil {
+		b.err = key.err
+		return b
+	}
+	if err := key.isGetItemValid(); err != nil {
+		b.err = dynamoError().method(opGetBatch).wrap(err)
+		return b
+	}
+	b.keys = append(b.keys, getRequest{c: key.c, key: BatchKey{Table: key.c.tableName, Key: key.key}})
+	return b
+}
+
+// ConsistentRead makes the keys of the tables read with strongly consistent reads, bypassing the cache.
+func (b *GetBatch) ConsistentRead(tables ...string) *GetBatch {
+	for _, table := range tables {
+		b.consistent[table] = true
+	}
+	return b
+}
+
+// Fetch fetches the items of the keys, running threadCount BatchGetItem calls in parallel.
+func (b *GetBatch) Fetch(ctx context.Context, threadCount int) (*GetBatchResult, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	result := &GetBatchResult{Items: make([]map[string]types.AttributeValue, len(b.keys)), index: make(map[string]int)}
+	found := make(map[string]map[string]types.AttributeValue)
+	var mu sync.Mutex
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(threadCount)
+	for _, chunk := range b.chunks(found) {
+		chunk := chunk
+		g.Go(func() error {
+			return chunk.fetch(ctx, found, &mu)
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	for n, r := range b.keys {
+		id := cacheKey(r.key.Table, r.key.Key)
+		result.index[id] = n
+		if item, ok := found[id]; ok {
+			result.Items[n] = item
+			continue
+		}
+		result.Missing = append(result.Missing, r.key)
+	}
+	return result, nil
+}
+
+// Item returns the item of the key set on key, and whether the key has an item.
+// It returns false as well for keys that weren't added to the GetBatch.
+func (r *GetBatchResult) Item(key *Item) (map[string]types.AttributeValue, bool) {
+	if key.c == nil {
+		return nil, false
+	}
+	n, ok := r.index[cacheKey(key.c.tableName, key.key)]
+	if !ok || r.Items[n] == nil {
+		return nil, false
+	}
+	return r.Items[n], true
+}
+
+// chunks packs the distinct keys of the batch that aren't served by the cache in chunks of up to getBatchSize keys,
+// one DynamoDB client per chunk. The items served by the cache are added to found.
+func (b *GetBatch) chunks(found map[string]map[string]types.AttributeValue) []*getChunk {
+	var chunks []*getChunk
+	open := make(map[*dynamodb.Client]*getChunk)
+	seen := make(map[string]bool)
+	for _, r := range b.keys {
+		id := cacheKey(r.key.Table, r.key.Key)
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		consistent := b.consistent[r.key.Table]
+		if r.c.cache != nil && !consistent {
+			if item, ok := r.c.cachedItem(r.key.Table, r.key.Key); ok {
+				found[id] = item
+				continue
+			}
+		}
+
+		chunk := open[r.c.client]
+		if chunk == nil || chunk.count == getBatchSize {
+			chunk = &getChunk{
+				tables:   batchTables{def: r.c, clients: make(map[string]*Client)},
+				requests: make(map[string]types.KeysAndAttributes),
+			}
+			open[r.c.client] = chunk
+			chunks = append(chunks, chunk)
+		}
+		chunk.tables.clients[r.key.Table] = r.c
+		keysAndAttributes := chunk.requests[r.key.Table]
+		keysAndAttributes.Keys = append(keysAndAttributes.Keys, r.key.Key)
+		if consistent {
+			keysAndAttributes.ConsistentRead = aws.Bool(true)
+		}
+		chunk.requests[r.key.Table] = keysAndAttributes
+		chunk.count++
+	}
+	return chunks
+}
+
+// fetch fetches the items of the keys of the chunk and adds them to found, by key.
+func (chunk *getChunk) fetch(ctx context.Context, found map[string]map[string]types.AttributeValue, mu *sync.Mutex) error {
+	generations := make(map[string]uint64, len(chunk.requests))
+	for table := range chunk.requests {
+		c := chunk.tables.client(table)
+		generations[table] = c.beginCacheFill()
+		defer c.endCacheFill()
+	}
+
+	paginator := newBatchGetItemPaginator(chunk.tables.def.client, &dynamodb.BatchGetItemInput{RequestItems: chunk.requests})
+	for paginator.hasMorePages() {
+		page, err := paginator.nextPage(ctx)
+		if err != nil {
+			return chunk.tables.def.opError(opGetBatch, nil, err)
+		}
+		for table, items := range page.Responses {
+			c := chunk.tables.client(table)
+			if err := c.decodeItems(ctx, items); err != nil {
+				return dynamoError().method(opGetBatch).wrap(err)
+			}
+			requested := chunk.requests[table]
+			for _, item := range items {
+				key := keyOf(item, requested.Keys[0])
+				if requested.ConsistentRead == nil {
+					c.cacheItem(generations[table], table, key, item)
+				}
+				mu.Lock()
+				found[cacheKey(table, key)] = item
+				mu.Unlock()
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package dygo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_get_batch(t *testing.T) {
+	fake := newFakeDynamoDB()
+	rooms := newFakeClient(t, fake)
+	bookings := &Client{
+		client:       rooms.client,
+		tableName:    "test-table-2",
+		partitionKey: "_partition_key",
+		sortKey:      "_sort_key",
+		keySeparator: "#",
+	}
+	var mu sync.Mutex
+	consistent := make(map[string]bool)
+	fake.hook = func(op string, req map[string]json.RawMessage) (int, any) {
+		var requests map[string]struct{ ConsistentRead bool }
+		json.Unmarshal(req["RequestItems"], &requests)
+		mu.Lock()
+		for table, r := range requests {
+			consistent[table] = r.ConsistentRead
+		}
+		mu.Unlock()
+		return 0, nil
+	}
+
+	item := func(pk, name string) map[string]types.AttributeValue {
+		return map[string]types.AttributeValue{
+			"_partition_key": &types.AttributeValueMemberS{Value: pk},
+			"_sort_key":      &types.AttributeValueMemberS{Value: "current"},
+			"name":           &types.AttributeValueMemberS{Value: name},
+		}
+	}
+	// the same key in both tables
+	fake.put("test-table-1", item("id-1", "room"))
+	fake.put("test-table-2", item("id-1", "booking"))
+
+	batch := NewGetBatch()
+	pks := make([]string, 150)
+	for n := range pks {
+		pks[n] = newPK("room")
+		if n%2 == 0 {
+			fake.put("test-table-1", item(pks[n], pks[n]))
+		}
+		batch.Get(rooms.PK(pks[n]).SK(Equal("current")))
+	}
+	result, err := batch.
+		Get(bookings.PK("id-1").SK(Equal("current"))).
+		Get(rooms.PK("id-1").SK(Equal("current"))).
+		Get(bookings.PK("id-2").SK(Equal("current"))).
+		Get(rooms.PK(pks[0]).SK(Equal("current"))).
+		ConsistentRead("test-table-2").
+		Fetch(context.Background(), 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, fake.callCount("BatchGetItem"))
+	assert.Equal(t, map[string]bool{"test-table-1": false, "test-table-2": true}, consistent)
+
+	if assert.Len(t, result.Items, 154) {
+		for n, pk := range pks {
+			if n%2 == 0 {
+				assert.Equal(t, &types.AttributeValueMemberS{Value: pk}, result.Items[n]["name"])
+			} else {
+				assert.Nil(t, result.Items[n])
+			}
+		}
+		assert.Equal(t, &types.AttributeValueMemberS{Value: "booking"}, result.Items[150]["name"])
+		assert.Equal(t, &types.AttributeValueMemberS{Value: "room"}, result.Items[151]["name"])
+		assert.Nil(t, result.Items[152])
+		assert.Equal(t, result.Items[0], result.Items[153])
+	}
+	assert.Len(t, result.Missing, 76)
+	assert.Equal(t, BatchKey{Table: "test-table-2", Key: map[string]types.AttributeValue{
+		"_partition_key": &types.AttributeValueMemberS{Value: "id-2"},
+		"_sort_key":      &types.AttributeValueMemberS{Value: "current"},
+	}}, result.Missing[75])
+
+	found, ok := result.Item(bookings.PK("id-1").SK(Equal("current")))
+	assert.True(t, ok)
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "booking"}, found["name"])
+	_, ok = result.Item(rooms.PK(pks[1]).SK(Equal("current")))
+	assert.False(t, ok)
+	_, ok = result.Item(new(Item))
+	assert.False(t, ok)
+
+	_, err = NewGetBatch().Get(rooms.PK("")).Fetch(context.Background(), 1)
+	assert.Error(t, err)
+}
+
+func Test_get_batch_number_keys(t *testing.T) {
+	fake := newFakeDynamoDB()
+	c := newFakeClient(t, fake)
+	// DynamoDB returns number keys normalized
+	fake.hook = func(op string, req map[string]json.RawMessage) (int, any) {
+		return http.StatusOK, map[string]any{"Responses": map[string]any{"test-table-1": []any{
+			encodeFakeItem(map[string]types.AttributeValue{
+				"_partition_key": &types.AttributeValueMemberS{Value: "room#1"},
+				"_sort_key":      &types.AttributeValueMemberN{Value: "1.5"},
+			}),
+		}}}
+	}
+
+	result, err := NewGetBatch().
+		Get(c.PK("room#1").SK(Equal(&types.AttributeValueMemberN{Value: "1.50"}))).
+		Fetch(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	assert.Empty(t, result.Missing)
+	assert.NotNil(t, result.Items[0])
+	assert.Equal(t, cacheKey("t", map[string]types.AttributeValue{"n": &types.AttributeValueMemberN{Value: "7"}}),
+		cacheKey("t", map[string]types.AttributeValue{"n": &types.AttributeValueMemberN{Value: "007"}}))
+}
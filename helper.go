@@ -1,22 +1,73 @@
 package dygo
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 )
 
-// createAttributeValue creates a types.AttributeValue based on the input type.
-func createAttributeValue(key interface{}) types.AttributeValue {
+// createAttributeValue creates the types.AttributeValue of a key value.
+// Key values are scalars: strings and time.Time values become strings, numbers become numbers and byte slices binaries,
+// as attributevalue.Marshal encodes them. An attribute value is used as is.
+func createAttributeValue(key interface{}) (types.AttributeValue, error) {
 	switch k := key.(type) {
+	case *types.AttributeValueMemberS, *types.AttributeValueMemberN, *types.AttributeValueMemberB:
+		return k.(types.AttributeValue), nil
+	case string, []byte, time.Time,
+		int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64,
+		float32, float64:
+		return attributevalue.Marshal(k)
+	}
+	return nil, fmt.Errorf("unsupported key value type %T, expected a string, number or binary", key)
+}
+
+// attributeTypeOf returns the type of a key attribute value.
+func attributeTypeOf(av types.AttributeValue) AttributeType {
+	switch av.(type) {
+	case *types.AttributeValueMemberS:
+		return AttributeTypeString
+	case *types.AttributeValueMemberN:
+		return AttributeTypeNumber
+	case *types.AttributeValueMemberB:
+		return AttributeTypeBinary
+	}
+	return ""
+}
+
+// keyValue returns the attribute value of the key attribute, checked against the type declared with WithAttributeType.
+func (c *Client) keyValue(attributeName string, value any) (types.AttributeValue, error) {
+	av, err := createAttributeValue(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", attributeName, err)
+	}
+	if want, ok := c.attributeTypes[attributeName]; ok && attributeTypeOf(av) != want {
+		return nil, fmt.Errorf("%s: key attribute is declared as %s, got %s", attributeName, want, attributeTypeOf(av))
+	}
+	return av, nil
+}
+
+// isEmptyKeyValue reports whether a key value is missing: nil, an empty string or an empty byte slice.
+func isEmptyKeyValue(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
 	case string:
-		return &types.AttributeValueMemberS{Value: k}
-	case int:
-		return &types.AttributeValueMemberN{Value: strconv.Itoa(k)}
+		return v == ""
+	case []byte:
+		return len(v) == 0
+	case *types.AttributeValueMemberS:
+		return v.Value == ""
+	case *types.AttributeValueMemberB:
+		return len(v.Value) == 0
 	}
-	return nil
+	return false
 }
 
 // validate validates the given key and value.
@@ -43,19 +94,19 @@ func (i *Item) validate(key string, value any) error {
 // validateTableName checks if the provided table name is empty.
 func (i *Item) validateTableName(value any) error {
 	if value == "" {
-		return dynamoError().method("TableName").message("table name can't be empty")
+		return validationError().method("TableName").message("table name can't be empty")
 	}
 	return nil
 }
 
 // validateFilterOr validates the filter OR condition for an Item.
 func (i *Item) validateFilterOr(value any) error {
-	return dynamoError().method("FilterAnd").message("invalid filter OR condition")
+	return validationError().method("FilterAnd").message("invalid filter OR condition")
 }
 
 // validateFilterAnd validates the filter AND condition for an Item.
 func (i *Item) validateFilterAnd(value any) error {
-	return dynamoError().method("FilterAnd").message("invalid filter AND condition")
+	return validationError().method("FilterAnd").message("invalid filter AND condition")
 }
 
 // validateGSI validates the Global Secondary Index (GSI) value.
@@ -67,15 +118,15 @@ func (i *Item) validateGSI(value any) error {
 		}
 	}
 	if !found {
-		return dynamoError().method("GSI").message("invalid GSI name")
+		return validationError().method("GSI").message("invalid GSI name")
 	}
 	return nil
 }
 
 // validatePartitionKey checks if the provided partition key value is empty.
 func (i *Item) validatePartitionKey(value any) error {
-	if value == "" {
-		return dynamoError().method("PK").message("partition key can't be empty")
+	if isEmptyKeyValue(value) {
+		return validationError().method("PK").message("partition key can't be empty")
 	}
 	return nil
 }
@@ -86,11 +137,11 @@ func (i *Item) validateSortKey(value any) error {
 	if i.c.sortKey == "" {
 		msg = "sort key is not required"
 	}
-	if i.c.sortKey != "" && value == "" {
+	if i.c.sortKey != "" && isEmptyKeyValue(value) {
 		msg = "sort key can't be empty"
 	}
 	if msg != "" {
-		return dynamoError().method("SK").message(msg)
+		return validationError().method("SK").message(msg)
 	}
 	return nil
 }
@@ -100,7 +151,7 @@ func (i *Item) validateProjecion(value any) error {
 	msg := ""
 	if value == "" {
 		msg = "projection can't be empty"
-		return dynamoError().method("Projection").message(msg)
+		return validationError().method("Projection").message(msg)
 	}
 	return nil
 }
@@ -202,3 +253,15 @@ func getPartitionKey(i *Item) string {
 
 	return i.c.gsis[0].partitionKey
 }
+
+// sleepContext waits for the duration d, returning early with the context error if ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,223 @@
+package dygo
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"golang.org/x/sync/errgroup"
+)
+
+const opImport = "Import"
+
+const (
+	defaultImportChunkSize   = 250
+	defaultImportThreadCount = 10
+)
+
+// ConflictPolicy decides what Import does with items whose key already exists in the table.
+type ConflictPolicy int
+
+const (
+	// ConflictOverwrite replaces existing items. Items are written with BatchWriteItem.
+	ConflictOverwrite ConflictPolicy = iota
+	// ConflictSkip keeps existing items and skips the imported ones. Items are written with conditional PutItem calls.
+	ConflictSkip
+	// ConflictFail stops the import at the first item that already exists.
+	// Items are written with conditional PutItem calls.
+	ConflictFail
+)
+
+// ImportOptions configures Import.
+type ImportOptions struct {
+	// Format is the line format, FormatDynamoDBJSON by default.
+	Format Format
+	// Conflict is the policy for items that already exist, ConflictOverwrite by default.
+	Conflict ConflictPolicy
+	// Offset is the number of lines to skip, e.g. the Lines of the result of a failed import to resume it.
+	Offset int
+	// ChunkSize is the number of lines written before progress is reported, 250 by default.
+	ChunkSize int
+	// ThreadCount is the number of parallel write requests, 10 by default.
+	ThreadCount int
+	// Progress, if set, is called after every written chunk.
+	Progress func(ImportResult)
+}
+
+// ImportResult reports the progress of an import.
+type ImportResult struct {
+	// Lines is the number of lines read and written, including the skipped offset.
+	// If Import fails, it is the offset to resume from.
+	Lines int
+	// Written is the number of written items.
+	Written int
+	// Skipped is the number of existing items kept by ConflictSkip.
+	Skipped int
+}
+
+// importLine is an item decoded from a line of an import.
+type importLine struct {
+	number int
+	item   map[string]types.AttributeValue
+}
+
+// Import reads JSON lines as written by Export from r and writes the items to the table.
+// Items are written as they are in the lines, so a stored export restores encrypted, compressed and
+// offloaded attributes unchanged. Blank lines are ignored.
+// When resuming a failed import with ConflictFail, use ConflictSkip for the resumed run,
+// as items of the failed chunk may already be written.
+//
+// Example:
+//
+//	f, err := os.Open("backup.jsonl")
+//	...
+//	result, err := db.Import(context.Background(), f, ImportOptions{
+//		Conflict: ConflictSkip,
+//		Progress: func(r ImportResult) { log.Println(r.Lines) },
+//	})
+//	if err != nil {
+//		// retry later with ImportOptions{Offset: result.Lines}
+//	}
+func (c *Client) Import(ctx context.Context, r io.Reader, opts ImportOptions) (ImportResult, error) {
+	format := opts.Format
+	if format == "" {
+		format = FormatDynamoDBJSON
+	}
+	if format != FormatDynamoDBJSON && format != FormatJSON {
+		return ImportResult{}, validationError().method(opImport).message(fmt.Sprintf("unknown format %q", format))
+	}
+	if opts.ChunkSize <= 0 {
+		opts.ChunkSize = defaultImportChunkSize
+	}
+	if opts.ThreadCount <= 0 {
+		opts.ThreadCount = defaultImportThreadCount
+	}
+
+	result := ImportResult{Lines: opts.Offset}
+	br := bufio.NewReader(r)
+	var chunk []importLine
+	number := 0
+	for {
+		line, readErr := br.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			return result, dynamoError().method(opImport).wrap(readErr)
+		}
+		if len(line) > 0 {
+			number++
+		}
+		if len(line) > 0 && number > opts.Offset {
+			if line = bytes.TrimSpace(line); len(line) > 0 {
+				item, err := decodeExportLine(line, format)
+				if err != nil {
+					return result, validationError().method(opImport).wrap(fmt.Errorf("line %d: %w", number, err))
+				}
+				chunk = append(chunk, importLine{number: number, item: item})
+			}
+		}
+
+		if number > result.Lines && (len(chunk) >= opts.ChunkSize || readErr == io.EOF) {
+			written, skipped, err := c.importChunk(ctx, chunk, opts)
+			if err != nil {
+				return result, dynamoError().method(opImport).wrap(err)
+			}
+			result.Lines = number
+			result.Written += written
+			result.Skipped += skipped
+			chunk = chunk[:0]
+			if opts.Progress != nil {
+				opts.Progress(result)
+			}
+		}
+		if readErr == io.EOF {
+			return result, nil
+		}
+	}
+}
+
+// importChunk writes the items of the chunk according to the conflict policy,
+// returning the number of written and skipped items.
+func (c *Client) importChunk(ctx context.Context, chunk []importLine, opts ImportOptions) (int, int, error) {
+	if len(chunk) == 0 {
+		return 0, 0, nil
+	}
+	if opts.Conflict == ConflictOverwrite {
+		return c.importOverwrite(ctx, chunk, opts.ThreadCount)
+	}
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(opts.ThreadCount)
+	var mu sync.Mutex
+	var written, skipped int
+	for _, l := range chunk {
+		l := l
+		g.Go(func() error {
+			err := c.putIfNotExists(ctx, l.item)
+			switch {
+			case errors.Is(err, ErrAlreadyExists) && opts.Conflict == ConflictSkip:
+				mu.Lock()
+				skipped++
+				mu.Unlock()
+				return nil
+			case errors.Is(err, ErrAlreadyExists):
+				return fmt.Errorf("line %d: item with key %s already exists: %w", l.number, keyString(c.primaryKey(l.item)), ErrAlreadyExists)
+			case err != nil:
+				return fmt.Errorf("line %d: %w", l.number, err)
+			}
+			mu.Lock()
+			written++
+			mu.Unlock()
+			return nil
+		})
+	}
+	err := g.Wait()
+	return written, skipped, err
+}
+
+// importOverwrite writes the items of the chunk through the batch upsert path.
+// A key occurring more than once in the chunk is written with its last item, as a batch can't contain duplicate keys.
+func (c *Client) importOverwrite(ctx context.Context, chunk []importLine, threadCount int) (int, int, error) {
+	last := make(map[string]int, len(chunk))
+	for n, l := range chunk {
+		last[keyString(c.primaryKey(l.item))] = n
+	}
+
+	batch := new(Item)
+	for n, l := range chunk {
+		if last[keyString(c.primaryKey(l.item))] != n {
+			continue
+		}
+		c.ItemRaw(l.item).AddBatchUpsertRawItem(batch)
+		if batch.err != nil {
+			return 0, 0, fmt.Errorf("line %d: %w", l.number, batch.err)
+		}
+	}
+	if err := batch.BatchUpsertItem(ctx, threadCount); err != nil {
+		return 0, 0, err
+	}
+	return len(last), 0, nil
+}
+
+// putIfNotExists writes the item unless an item with the same key exists.
+func (c *Client) putIfNotExists(ctx context.Context, item map[string]types.AttributeValue) error {
+	if err := c.checkItemSize(item); err != nil {
+		return err
+	}
+	_, err := c.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:                aws.String(c.tableName),
+		Item:                     item,
+		ConditionExpression:      aws.String("attribute_not_exists(#pk)"),
+		ExpressionAttributeNames: map[string]string{"#pk": c.partitionKey},
+	})
+	if err != nil {
+		return c.opError(opImport, c.primaryKey(item), err)
+	}
+	c.invalidateCache(c.tableName, c.primaryKey(item))
+	return nil
+}
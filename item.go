@@ -14,8 +14,10 @@ type Item struct {
 	c                         *Client
 	indexName                 string
 	customEntityTypeAttribute string
-	projection                string
+	projection                []string
 	useGSI                    bool
+	bypassCache               bool
+	continueOnError           bool
 	item                      ItemData
 	err                       error
 	batchData                 keys
@@ -24,6 +26,7 @@ type Item struct {
 	condition                 expression.ConditionBuilder
 	key                       map[string]types.AttributeValue // required only for GetItem/DeleteItem
 	keyCondition              expression.KeyConditionBuilder
+	statement                 statement // required only for PartiQL statements
 }
 
 // ItemData is an interface that represents a DynamoDB item. Each data item must implement this interface.
@@ -34,10 +37,11 @@ type ItemData interface {
 
 type keys struct {
 	batchGet    map[int]map[string]types.KeysAndAttributes
-	batchPut    map[int]map[string][]types.WriteRequest
+	batchPut    []batchPutItem
 	batchDelete map[int]map[string][]types.WriteRequest
 	batchPutRaw map[string]types.AttributeValue
 	updateItems []updateItem
+	statements  []types.BatchStatementRequest
 }
 
 type pagination struct {
@@ -46,9 +50,20 @@ type pagination struct {
 	desc             bool
 }
 
+// batchPutItem is an item added to a batch upsert. Items added with AddBatchUpsertItem are marshalled when added
+// and transformed according to their dygo struct tags when the batch runs, with the context of the run.
+type batchPutItem struct {
+	table     string
+	item      map[string]types.AttributeValue
+	fields    []fieldOptions
+	transform bool
+}
+
 type updateItem struct {
 	updateItem map[string]types.AttributeValue
 	key        map[string]types.AttributeValue
+	update     expression.UpdateBuilder
+	condition  expression.ConditionBuilder
 }
 
 const (
@@ -60,17 +75,23 @@ const (
 // partition creates a new Item with the specified key and value, and associates it with the Client.
 // It returns a pointer to the created Item.
 func (c *Client) partition(key string, value any) *Item {
-	k := make(map[string]types.AttributeValue)
-	k[key] = createAttributeValue(value)
 	item := Item{
-		c:            c,
-		key:          k,
-		keyCondition: expression.KeyEqual(expression.Key(key), expression.Value(value.(string))),
+		c:   c,
+		key: make(map[string]types.AttributeValue),
 	}
 	item.err = item.validate("TableName", c.tableName)
 	if item.err == nil {
 		item.err = item.validate("PK", value)
 	}
+	av, err := c.keyValue(key, value)
+	if err != nil {
+		if item.err == nil {
+			item.err = validationError().method("PK").wrap(err)
+		}
+		return &item
+	}
+	item.key[key] = av
+	item.keyCondition = expression.KeyEqual(expression.Key(key), expression.Value(av))
 	return &item
 }
 
@@ -92,19 +113,41 @@ func (i *Item) sort(key string, f SortKeyFunc) *Item {
 	if i.err == nil {
 		i.err = i.validate("SK", sortKeyValue)
 	}
-	i.key[key] = createAttributeValue(sortKeyValue)
+	av, err := i.c.keyValue(key, sortKeyValue)
+	if err != nil {
+		if i.err == nil {
+			i.err = validationError().method("SK").wrap(err)
+		}
+		return i
+	}
+	i.key[key] = av
 	i.keyCondition = i.keyCondition.And(keyCondition)
 	return i
 }
 
 // buildFilter builds a filter for the Item based on the provided attributeName and FilterFunc.
 func (i *Item) buildFilter(attributeName string, f FilterFunc) *Item {
-	i.filter = f(attributeName)
+	condition, err := i.c.filterCondition(attributeName, f)
+	if err != nil {
+		if i.err == nil {
+			i.err = validationError().method("Filter").wrap(err)
+		}
+		return i
+	}
+	i.filter = condition
 	return i
 }
 
 // buildCondition builds a condition for the Item based on the provided attributeName and ConditionFunc.
 func (i *Item) buildCondition(attributeName string, f ConditionFunc) *Item {
+	if attributeName != "" {
+		if _, err := attributePath(attributeName); err != nil {
+			if i.err == nil {
+				i.err = validationError().method("Condition").wrap(err)
+			}
+			return i
+		}
+	}
 	i.condition = f(attributeName)
 	return i
 }
@@ -116,7 +159,13 @@ func (i *Item) buildAndFilter(attributeName string, f FilterFunc) *Item {
 		i.err = i.validate("FilterAnd", none)
 		return i
 	}
-	condition := f(attributeName)
+	condition, err := i.c.filterCondition(attributeName, f)
+	if err != nil {
+		if i.err == nil {
+			i.err = validationError().method("FilterAnd").wrap(err)
+		}
+		return i
+	}
 	i.filter = i.filter.And(condition)
 	return i
 }
@@ -127,16 +176,30 @@ func (i *Item) buildOrFilter(attributeName string, f FilterFunc) *Item {
 		i.err = i.validate("FilterOr", none)
 		return i
 	}
-	condition := f(attributeName)
+	condition, err := i.c.filterCondition(attributeName, f)
+	if err != nil {
+		if i.err == nil {
+			i.err = validationError().method("FilterOr").wrap(err)
+		}
+		return i
+	}
 	i.filter = i.filter.Or(condition)
 	return i
 }
 
 // setProjection sets the projection value for the Item.
 func (i *Item) setProjection(value []string) *Item {
-	i.projection = strings.Join(value, ",")
+	i.projection = value
 	if i.err == nil {
-		i.err = i.validate("Projection", i.projection)
+		i.err = i.validate("Projection", strings.Join(value, ","))
+	}
+	for _, path := range value {
+		if i.err != nil {
+			break
+		}
+		if _, err := attributePath(path); err != nil {
+			i.err = validationError().method("Projection").wrap(err)
+		}
 	}
 	return i
 }
@@ -155,7 +218,14 @@ func (i *Item) setProjection(value []string) *Item {
 func (i *Item) LastEvaluatedKey(keys map[string]any) *Item {
 	i.pagination.lastEvaluatedKey = make(map[string]types.AttributeValue)
 	for key, value := range keys {
-		i.pagination.lastEvaluatedKey[key] = createAttributeValue(value)
+		av, err := i.c.keyValue(key, value)
+		if err != nil {
+			if i.err == nil {
+				i.err = validationError().method("LastEvaluatedKey").wrap(err)
+			}
+			return i
+		}
+		i.pagination.lastEvaluatedKey[key] = av
 	}
 	return i
 }
@@ -184,9 +254,26 @@ func (c *Client) secondaryIndex(indexName string, partitionKeyValue any, f SortK
 	}
 	for _, sIndex := range c.gsis {
 		if sIndex.indexName == indexName {
-			keyCondition := expression.KeyEqual(expression.Key(sIndex.partitionKey), expression.Value(partitionKeyValue.(string)))
+			if attributeName, ok := c.blindIndexSource(sIndex.partitionKey); ok {
+				index, err := c.blindIndexOf(attributeName, partitionKeyValue)
+				if err != nil {
+					item.err = validationError().method("GSI").wrap(err)
+					return item
+				}
+				partitionKeyValue = index
+			}
+			av, err := c.keyValue(sIndex.partitionKey, partitionKeyValue)
+			if err != nil {
+				item.err = validationError().method("GSI").wrap(err)
+				return item
+			}
+			keyCondition := expression.KeyEqual(expression.Key(sIndex.partitionKey), expression.Value(av))
 			if f != nil {
-				sortKeyCond, _ := f(sIndex.sortKey)
+				sortKeyCond, sortKeyValue := f(sIndex.sortKey)
+				if _, err := c.keyValue(sIndex.sortKey, sortKeyValue); err != nil {
+					item.err = validationError().method("GSI").wrap(err)
+					return item
+				}
 				keyCondition = keyCondition.And(sortKeyCond)
 			}
 			item.indexName = indexName
@@ -303,51 +390,27 @@ func (i *Item) findBatchDeleteIndexIfBatchFull(batchIndex int) int {
 }
 
 // addBatchUpsertItem adds the current item to the batch put operation.
+// Raw items are added as is, the other items are transformed according to their dygo struct tags by putBatches.
 func (i *Item) addBatchUpsertItem(isRaw bool) {
-	if i.batchData.batchPut == nil {
-		i.batchData.batchPut = make(map[int]map[string][]types.WriteRequest)
-	}
-	batchIndex := i.findBatchPutIndex()
-	if _, ok := i.batchData.batchPut[batchIndex][i.c.tableName]; !ok {
-		i.batchData.batchPut[batchIndex][i.c.tableName] = []types.WriteRequest{}
-	}
-	batchIndex = i.findBatchPutIndexIfBatchFull(batchIndex)
-
-	var itemJson map[string]types.AttributeValue
 	if isRaw {
-		itemJson = i.batchData.batchPutRaw
-	} else {
-		itemJson, _ = attributevalue.MarshalMap(i.item)
-	}
-
-	i.batchData.batchPut[batchIndex][i.c.tableName] = append(i.batchData.batchPut[batchIndex][i.c.tableName], types.WriteRequest{
-		PutRequest: &types.PutRequest{
-			Item: itemJson,
-		},
-	})
-}
-
-// findBatchPutIndex returns the index of the current batch in the Item's batchPut slice.
-func (i *Item) findBatchPutIndex() int {
-	batchIndex := len(i.batchData.batchPut) - 1
-	if batchIndex < 0 {
-		batchIndex++
-		i.batchData.batchPut[batchIndex] = make(map[string][]types.WriteRequest)
-	}
-	return batchIndex
-}
-
-// findBatchPutIndexIfBatchFull finds the index of the batch in the Item's if current batch is full.
-func (i *Item) findBatchPutIndexIfBatchFull(batchIndex int) int {
-	batchIndexLength := 0
-	for _, keys := range i.batchData.batchPut[batchIndex] {
-		batchIndexLength += len(keys)
+		if err := i.c.checkItemSize(i.batchData.batchPutRaw); err != nil {
+			i.err = dynamoError().method(opBatchUpsert).wrap(err)
+			return
+		}
+		i.batchData.batchPut = append(i.batchData.batchPut, batchPutItem{table: i.c.tableName, item: i.batchData.batchPutRaw})
+		return
 	}
-	if batchIndexLength >= writeBatchSize {
-		batchIndex++
-		i.batchData.batchPut[batchIndex] = make(map[string][]types.WriteRequest)
+	av, err := attributevalue.MarshalMap(i.item)
+	if err != nil {
+		i.err = dynamoError().method(opBatchUpsert).wrap(err)
+		return
 	}
-	return batchIndex
+	i.batchData.batchPut = append(i.batchData.batchPut, batchPutItem{
+		table:     i.c.tableName,
+		item:      av,
+		fields:    taggedFields(i.item),
+		transform: true,
+	})
 }
 
 func (i *Item) getUpdateItemKey(index int) map[string]types.AttributeValue {
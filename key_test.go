@@ -0,0 +1,110 @@
+package dygo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_scalar_keys(t *testing.T) {
+	c := &Client{tableName: "test-table-1", partitionKey: "_partition_key", sortKey: "_sort_key", gsis: []gsi{{"gsi-name", "tenant_id", "created_at"}}}
+	opened := time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		pk   any
+		sk   any
+		want map[string]types.AttributeValue
+	}{
+		{
+			name: "number",
+			pk:   int64(42),
+			sk:   1.5,
+			want: map[string]types.AttributeValue{
+				"_partition_key": &types.AttributeValueMemberN{Value: "42"},
+				"_sort_key":      &types.AttributeValueMemberN{Value: "1.5"},
+			},
+		},
+		{
+			name: "binary and time",
+			pk:   []byte{1, 2},
+			sk:   opened,
+			want: map[string]types.AttributeValue{
+				"_partition_key": &types.AttributeValueMemberB{Value: []byte{1, 2}},
+				"_sort_key":      &types.AttributeValueMemberS{Value: "2023-04-01T00:00:00Z"},
+			},
+		},
+		{
+			name: "attribute values",
+			pk:   &types.AttributeValueMemberN{Value: "7"},
+			sk:   uint8(3),
+			want: map[string]types.AttributeValue{
+				"_partition_key": &types.AttributeValueMemberN{Value: "7"},
+				"_sort_key":      &types.AttributeValueMemberN{Value: "3"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := c.PK(tt.pk).SK(Equal(tt.sk))
+			assert.NoError(t, i.err)
+			assert.Equal(t, tt.want, i.key)
+			assert.False(t, i.isPartitionKeyEmpty())
+		})
+	}
+
+	i := c.GSI("gsi-name", 42, Between(opened, opened.Add(time.Hour)))
+	assert.NoError(t, i.err)
+	i = c.InitScan().LastEvaluatedKey(map[string]any{"_partition_key": 42, "_sort_key": []byte{1}})
+	assert.NoError(t, i.err)
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "42"}, i.pagination.lastEvaluatedKey["_partition_key"])
+
+	assert.Error(t, c.PK(struct{}{}).err)
+	assert.Error(t, c.PK([]byte{}).err)
+	assert.Error(t, c.PK(1).SK(Equal(true)).err)
+	assert.Error(t, c.InitScan().LastEvaluatedKey(map[string]any{"_partition_key": []string{"a"}}).err)
+}
+
+func Test_declared_key_types(t *testing.T) {
+	c := &Client{tableName: "test-table-1", partitionKey: "_partition_key", sortKey: "_sort_key", gsis: []gsi{{"gsi-name", "tenant_id", "created_at"}}}
+	for _, opt := range []Option{
+		WithAttributeType("_partition_key", AttributeTypeNumber),
+		WithAttributeType("tenant_id", AttributeTypeBinary),
+	} {
+		assert.NoError(t, opt(c))
+	}
+	assert.Error(t, WithAttributeType("_sort_key", AttributeTypeList)(c))
+
+	assert.NoError(t, c.PK(1).SK(Equal("current")).err)
+	assert.Error(t, c.PK("room#1").err)
+	assert.NoError(t, c.GSI("gsi-name", []byte{1}, nil).err)
+	assert.Error(t, c.GSI("gsi-name", "tenant", nil).err)
+	assert.Error(t, c.InitScan().LastEvaluatedKey(map[string]any{"_partition_key": "1"}).err)
+}
+
+func Test_scalar_keys_round_trip(t *testing.T) {
+	fake := newFakeDynamoDB()
+	c := newFakeClient(t, fake)
+	fake.put("test-table-1", map[string]types.AttributeValue{
+		"_partition_key": &types.AttributeValueMemberN{Value: "42"},
+		"_sort_key":      &types.AttributeValueMemberB{Value: []byte("v1")},
+		"name":           &types.AttributeValueMemberS{Value: "Main hall"},
+	})
+
+	var out struct {
+		Name string `dynamodbav:"name"`
+	}
+	err := c.PK(42).SK(Equal([]byte("v1"))).GetItem(context.Background(), &out)
+	assert.NoError(t, err)
+	assert.Equal(t, "Main hall", out.Name)
+
+	batch := new(Item)
+	c.PK(42).SK(Equal([]byte("v1"))).AddBatchGetItem(batch, true)
+	c.PK([]byte{}).SK(Equal([]byte("v1"))).AddBatchGetItem(batch, true)
+	items, err := batch.BatchGetItem(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Len(t, items, 1)
+}
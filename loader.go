@@ -0,0 +1,215 @@
+package dygo
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const opLoad = "Load"
+
+const (
+	defaultLoaderWait        = 2 * time.Millisecond
+	defaultLoaderThreadCount = 10
+)
+
+// Loader collects concurrent single item loads issued within a short window,
+// deduplicates their keys and fetches them together with BatchGetItem.
+// It is safe for concurrent use.
+type Loader struct {
+	c           *Client
+	wait        time.Duration
+	maxBatch    int
+	threadCount int
+
+	mu      sync.Mutex
+	pending *loaderBatch
+}
+
+// LoaderOption configures a Loader.
+type LoaderOption func(*Loader)
+
+// loaderBatch is a set of keys collected by a Loader that is fetched at once.
+// The fetch uses the context of the batch, which is canceled once no caller waits for the batch anymore.
+type loaderBatch struct {
+	items   []*Item
+	keys    map[string]bool
+	results map[string]map[string]types.AttributeValue
+	err     error
+	done    chan struct{}
+	timer   *time.Timer
+	ctx     context.Context
+	cancel  context.CancelFunc
+	waiters int
+}
+
+// WithLoaderWait sets how long the Loader collects keys before fetching them. It defaults to 2ms.
+func WithLoaderWait(wait time.Duration) LoaderOption {
+	return func(l *Loader) {
+		l.wait = wait
+	}
+}
+
+// WithLoaderMaxBatch sets the number of keys after which the Loader fetches immediately. It defaults to 100.
+func WithLoaderMaxBatch(size int) LoaderOption {
+	return func(l *Loader) {
+		if size > 0 {
+			l.maxBatch = size
+		}
+	}
+}
+
+// WithLoaderThreadCount sets the number of parallel BatchGetItem requests of a fetch. It defaults to 10.
+func WithLoaderThreadCount(threadCount int) LoaderOption {
+	return func(l *Loader) {
+		if threadCount > 0 {
+			l.threadCount = threadCount
+		}
+	}
+}
+
+// NewLoader returns a Loader fetching items of the client's table.
+//
+// Example:
+//
+//	loader := db.NewLoader(WithLoaderWait(time.Millisecond))
+//
+//	// called concurrently, e.g. from GraphQL resolvers
+//	d := dataItem{}
+//	err := loader.Load(ctx, db.PK(PK).SK(Equal(SK)), &d)
+//	if errors.Is(err, ErrNotFound) {
+//		// handle missing item
+//	}
+func (c *Client) NewLoader(opts ...LoaderOption) *Loader {
+	l := &Loader{
+		c:           c,
+		wait:        defaultLoaderWait,
+		maxBatch:    getBatchSize,
+		threadCount: defaultLoaderThreadCount,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Load fetches the item with the key set on item and unmarshals it into out.
+// The key is fetched together with all other keys loaded within the wait window of the Loader.
+// It returns an error wrapping ErrNotFound if the item doesn't exist.
+// Projections set on item are ignored, the Loader always fetches complete items.
+// The fetch of the batch is canceled once the contexts of all the callers waiting for it are done.
+func (l *Loader) Load(ctx context.Context, item *Item, out interface{}) error {
+	if item.err != nil {
+		return item.err
+	}
+	if item.c != l.c {
+		return dynamoError().method(opLoad).message("item doesn't belong to the client of the loader")
+	}
+	if err := item.isGetItemValid(); err != nil {
+		return dynamoError().method(opLoad).wrap(err)
+	}
+
+	key := cacheKey(l.c.tableName, item.key)
+	batch := l.enqueue(key, item)
+
+	select {
+	case <-batch.done:
+	case <-ctx.Done():
+		l.leave(batch)
+		return dynamoError().method(opLoad).wrap(ctx.Err())
+	}
+
+	if batch.err != nil {
+		return batch.err
+	}
+	result, ok := batch.results[key]
+	if !ok {
+		return dynamoError().method(opLoad).wrap(ErrNotFound)
+	}
+	if err := attributevalue.UnmarshalMap(result, &out); err != nil {
+		return dynamoError().method(opLoad).wrap(err)
+	}
+	return nil
+}
+
+// enqueue adds the key to the pending batch, starting a new batch if none is pending,
+// and returns the batch the key will be fetched with.
+func (l *Loader) enqueue(key string, item *Item) *loaderBatch {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	batch := l.pending
+	if batch == nil {
+		batch = &loaderBatch{keys: make(map[string]bool), done: make(chan struct{})}
+		batch.ctx, batch.cancel = context.WithCancel(context.Background())
+		l.pending = batch
+		batch.timer = time.AfterFunc(l.wait, func() {
+			l.mu.Lock()
+			if l.pending == batch {
+				l.pending = nil
+			}
+			l.mu.Unlock()
+			l.dispatch(batch)
+		})
+	}
+
+	batch.waiters++
+	if !batch.keys[key] {
+		batch.keys[key] = true
+		batch.items = append(batch.items, &Item{c: l.c, key: item.key})
+	}
+	if len(batch.items) >= l.maxBatch && l.pending == batch {
+		l.pending = nil
+		batch.timer.Stop()
+		go l.dispatch(batch)
+	}
+	return batch
+}
+
+// leave unregisters a caller that stopped waiting for the batch.
+// Once no caller waits for it, the batch is no longer pending and its fetch is canceled.
+func (l *Loader) leave(batch *loaderBatch) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	batch.waiters--
+	if batch.waiters > 0 {
+		return
+	}
+	if l.pending == batch {
+		l.pending = nil
+		batch.timer.Stop()
+	}
+	batch.cancel()
+}
+
+// dispatch fetches the keys of the batch and wakes up all callers waiting for it.
+// A batch can be dispatched by its timer and because it is full at the same time, only the first dispatch fetches.
+func (l *Loader) dispatch(batch *loaderBatch) {
+	l.mu.Lock()
+	if batch.results != nil || batch.err != nil {
+		l.mu.Unlock()
+		return
+	}
+	batch.results = make(map[string]map[string]types.AttributeValue, len(batch.items))
+	items := batch.items
+	l.mu.Unlock()
+
+	defer close(batch.done)
+	defer batch.cancel()
+
+	request := new(Item)
+	for _, item := range items {
+		item.AddBatchGetItem(request, false)
+	}
+	output, err := request.BatchGetItem(batch.ctx, l.threadCount)
+	if err != nil {
+		batch.err = err
+		return
+	}
+	for _, result := range output {
+		batch.results[cacheKey(l.c.tableName, keyOf(result, items[0].key))] = result
+	}
+}
@@ -0,0 +1,100 @@
+package dygo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_loader_batches_concurrent_loads(t *testing.T) {
+	fake := newFakeDynamoDB()
+	c := newFakeClient(t, fake)
+	for n := 0; n < 5; n++ {
+		fake.put(c.tableName, rawCachedItem(fmt.Sprintf("rm-%d", n), fmt.Sprintf("room %d", n)))
+	}
+
+	loader := c.NewLoader(WithLoaderWait(20 * time.Millisecond))
+	var wg sync.WaitGroup
+	names := make([]string, 10)
+	errs := make([]error, 10)
+	for n := 0; n < 10; n++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			out := cachedItem{}
+			// every key is loaded twice
+			errs[n] = loader.Load(context.Background(), c.PK(fmt.Sprintf("rm-%d", n%5)).SK(Equal("current")), &out)
+			names[n] = out.Name
+		}(n)
+	}
+	wg.Wait()
+
+	for n := 0; n < 10; n++ {
+		assert.NoError(t, errs[n])
+		assert.Equal(t, fmt.Sprintf("room %d", n%5), names[n])
+	}
+	assert.Equal(t, 1, fake.callCount("BatchGetItem"))
+}
+
+func Test_loader_flushes_full_batches(t *testing.T) {
+	fake := newFakeDynamoDB()
+	c := newFakeClient(t, fake)
+	fake.put(c.tableName, rawCachedItem("rm-1", "room 1"))
+
+	// the wait is long enough to time out the test if the full batch were not fetched immediately
+	loader := c.NewLoader(WithLoaderWait(time.Hour), WithLoaderMaxBatch(1))
+	out := cachedItem{}
+	err := loader.Load(context.Background(), c.PK("rm-1").SK(Equal("current")), &out)
+	assert.NoError(t, err)
+	assert.Equal(t, "room 1", out.Name)
+}
+
+func Test_loader_missing_item(t *testing.T) {
+	fake := newFakeDynamoDB()
+	c := newFakeClient(t, fake)
+
+	loader := c.NewLoader()
+	out := cachedItem{}
+	err := loader.Load(context.Background(), c.PK("rm-1").SK(Equal("current")), &out)
+	assert.True(t, errors.Is(err, ErrNotFound))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = c.NewLoader(WithLoaderWait(time.Hour)).Load(ctx, c.PK("rm-1").SK(Equal("current")), &out)
+	assert.True(t, errors.Is(err, context.Canceled))
+
+	other := &Client{tableName: "test-table-2", partitionKey: "_partition_key", sortKey: "_sort_key"}
+	assert.Error(t, loader.Load(context.Background(), other.PK("rm-1").SK(Equal("current")), &out))
+}
+
+func Test_loader_cancels_fetch_without_waiters(t *testing.T) {
+	fake := newFakeDynamoDB()
+	c := newFakeClient(t, fake)
+	started, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+	fake.hook = func(op string, req map[string]json.RawMessage) (int, any) {
+		close(started)
+		<-release
+		return 0, nil
+	}
+
+	loader := c.NewLoader(WithLoaderWait(time.Millisecond))
+	item := c.PK("rm-1").SK(Equal("current"))
+	batch := loader.enqueue(cacheKey(c.tableName, item.key), item)
+	<-started
+
+	// the only caller gives up, so the fetch doesn't wait for DynamoDB anymore
+	loader.leave(batch)
+	select {
+	case <-batch.done:
+	case <-time.After(time.Second):
+		t.Fatal("fetch still running without waiters")
+	}
+	assert.True(t, errors.Is(batch.err, context.Canceled), batch.err)
+}
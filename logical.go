@@ -0,0 +1,76 @@
+package dygo
+
+import "github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+
+// predicateFunc is the set of functions the logical combinators accept: FilterFunc and ConditionFunc.
+type predicateFunc interface {
+	FilterFunc | ConditionFunc
+}
+
+// And returns a FilterFunc or ConditionFunc that holds when all of the given ones hold.
+// Each of them is applied to the attribute passed to Filter or Condition, unless bound to another attribute with On.
+//
+// Example:
+//
+//	// (type = 'room' AND capacity > 20) OR (type = 'hall' AND NOT floor = 0)
+//	err = db.
+//		InitScan().
+//		Filter("", dygo.Or(
+//			dygo.And(dygo.On("type", dygo.KeyEqual("room")), dygo.On("capacity", dygo.KeyGreaterThan(20))),
+//			dygo.And(dygo.On("type", dygo.KeyEqual("hall")), dygo.Not(dygo.On("floor", dygo.KeyEqual(0)))),
+//		)).
+//		Scan(context.Background()).
+//		Unmarshal(&data, []string{"room"}).
+//		Run()
+func And[F predicateFunc](first, second F, others ...F) F {
+	return combine(expression.And, first, second, others)
+}
+
+// Or returns a FilterFunc or ConditionFunc that holds when any of the given ones holds.
+// Each of them is applied to the attribute passed to Filter or Condition, unless bound to another attribute with On.
+//
+// Example:
+//
+//	err = db.
+//		Item(newData).
+//		Condition("version", dygo.Or(dygo.ConditionAttributeNotExists(), dygo.ConditionLessThan(10))).
+//		Upsert(context.Background())
+func Or[F predicateFunc](first, second F, others ...F) F {
+	return combine(expression.Or, first, second, others)
+}
+
+// Not returns a FilterFunc or ConditionFunc that holds when the given one doesn't.
+//
+// Example:
+//
+//	err = db.
+//		GSI("gsi-name", "room", dygo.Equal("current")).
+//		Filter("physical_name", dygo.Not(dygo.KeyBeginsWith("tmp_"))).
+//		Query(context.Background()).
+//		Unmarshal(&data, []string{"room"}).
+//		Run()
+func Not[F predicateFunc](f F) F {
+	return F(func(keyName string) expression.ConditionBuilder {
+		return expression.Not(f(keyName))
+	})
+}
+
+// On binds a FilterFunc or ConditionFunc to the given attribute, whatever the attribute passed to Filter or Condition.
+// It is used to combine predicates on different attributes with And, Or and Not.
+// Attributes with a blind index can only be bound to BlindEqual, KeyEqual is rewritten only when set directly with Filter.
+func On[F predicateFunc](attributeName string, f F) F {
+	return F(func(string) expression.ConditionBuilder {
+		return f(attributeName)
+	})
+}
+
+// combine applies op to the conditions the functions build for the same attribute.
+func combine[F predicateFunc](op func(expression.ConditionBuilder, expression.ConditionBuilder, ...expression.ConditionBuilder) expression.ConditionBuilder, first, second F, others []F) F {
+	return F(func(keyName string) expression.ConditionBuilder {
+		rest := make([]expression.ConditionBuilder, len(others))
+		for n, f := range others {
+			rest[n] = f(keyName)
+		}
+		return op(first(keyName), second(keyName), rest...)
+	})
+}
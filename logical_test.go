@@ -0,0 +1,43 @@
+package dygo
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_logical_filters(t *testing.T) {
+	c := &Client{tableName: "test-table-1", partitionKey: "_partition_key"}
+	i := c.InitScan().Filter("", Or(
+		And(On("type", KeyEqual("room")), On("capacity", KeyGreaterThan(20))),
+		And(On("type", KeyEqual("hall")), Not(On("floor", KeyEqual(0)))),
+	))
+	assert.NoError(t, i.err)
+	expr := buildFilter(t, i.filter)
+	assert.Equal(t, "((#0 = :0) AND (#1 > :1)) OR ((#0 = :2) AND (NOT (#2 = :3)))", *expr.Filter())
+	assert.Equal(t, map[string]string{"#0": "type", "#1": "capacity", "#2": "floor"}, expr.Names())
+
+	i = c.InitScan().Filter("version", Or(KeyLessThan(3), KeyGreaterThan(10), KeyNull()))
+	expr = buildFilter(t, i.filter)
+	assert.Equal(t, "(#0 < :0) OR (#0 > :1) OR (attribute_not_exists (#0))", *expr.Filter())
+
+	i = c.Item(nil).Condition("version", Not(And(ConditionAttributeExists(), ConditionGreaterThan(3))))
+	condition, err := expression.NewBuilder().WithCondition(i.condition).Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "NOT ((attribute_exists (#0)) AND (#0 > :0))", *condition.Condition())
+}
+
+func Test_logical_filters_blind_index(t *testing.T) {
+	c := &Client{tableName: "test-table-1", partitionKey: "_partition_key", blindIndexes: map[string]string{"email": "email_bidx"}, blindIndexKey: []byte("key")}
+	i := c.InitScan().Filter("", And(On("email", KeyEqual("a@example.com")), On("type", KeyEqual("user"))))
+	assert.Error(t, i.err)
+
+	i = c.InitScan().Filter("email", KeyEqual("a@example.com"))
+	assert.NoError(t, i.err)
+
+	i = c.InitScan().Filter("", And(On("email", c.BlindEqual("a@example.com")), On("type", KeyEqual("user"))))
+	assert.NoError(t, i.err)
+	expr := buildFilter(t, i.filter)
+	assert.Equal(t, "email_bidx", expr.Names()["#0"])
+}
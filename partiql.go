@@ -0,0 +1,291 @@
+package dygo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	opStatement            = "Statement"
+	opBatchStatement       = "BatchStatement"
+	opTransactionStatement = "TransactionStatement"
+
+	statementBatchSize       = 25
+	statementTransactionSize = 100
+)
+
+type statement struct {
+	text       string
+	parameters []types.AttributeValue
+	nextToken  string
+}
+
+// StatementResult is the result of one statement of a BatchStatement call.
+// Item holds the item read by a SELECT statement, Err the error of a statement that failed,
+// which matches the sentinel error of its code, such as ErrConditionFailed or ErrThrottled.
+type StatementResult struct {
+	Item map[string]types.AttributeValue
+	Err  error
+}
+
+// Statement returns an Item holding a PartiQL statement.
+// The args are bound, in order, to the ? placeholders of the statement and are marshalled with attributevalue.Marshal.
+// Run the statement with Execute, or add it to a batch with AddBatchStatement.
+//
+// Parameters are written as given: they aren't encrypted, compressed, blind indexed or offloaded,
+// and the statement doesn't invalidate the item cache. INSERT, UPDATE and DELETE statements are therefore
+// rejected on a client configured with WithKeyProvider, WithCompression, WithBlindIndex, WithBlobStore or WithCache.
+//
+// Example:
+//
+//	var data []dataItem
+//	err := db.
+//		Statement(`SELECT * FROM "test-table-1" WHERE "_partition_key" = ?`, "room#1").
+//		Execute(context.Background()).
+//		Unmarshal(&data, []string{"room"}).
+//		Run()
+func (c *Client) Statement(text string, args ...any) *Item {
+	i := &Item{
+		c:         c,
+		statement: statement{text: text},
+	}
+	if text == "" {
+		i.err = validationError().method(opStatement).message("statement can't be empty")
+		return i
+	}
+	for n, arg := range args {
+		av, err := attributevalue.Marshal(arg)
+		if err != nil {
+			i.err = validationError().method(opStatement).message(fmt.Sprintf("parameter %d: %s", n+1, err.Error()))
+			return i
+		}
+		i.statement.parameters = append(i.statement.parameters, av)
+	}
+	if isWriteStatement(text) && c.transformsItems() {
+		i.err = validationError().method(opStatement).message("INSERT, UPDATE and DELETE statements aren't supported on a client with encryption, compression, blind indexes, blob offloading or a cache")
+	}
+	return i
+}
+
+// isWriteStatement reports whether the PartiQL statement writes items.
+func isWriteStatement(text string) bool {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return false
+	}
+	switch strings.ToUpper(fields[0]) {
+	case "INSERT", "UPDATE", "DELETE":
+		return true
+	}
+	return false
+}
+
+// transformsItems reports whether the client writes items other than as given,
+// or keeps copies of them that a write must invalidate.
+func (c *Client) transformsItems() bool {
+	return c.keyProvider != nil || c.compression != nil || len(c.blindIndexes) > 0 || c.blobStore != nil || c.cache != nil
+}
+
+// NextToken sets the token returned in the NextToken field of the output of Execute, to continue a read stopped by Limit.
+//
+// Example:
+//
+//	out := db.Statement(`SELECT * FROM "test-table-1"`).Limit(10).NextToken(token).Execute(context.Background())
+//	err := out.Unmarshal(&data, []string{"room"}).Run()
+//	token = out.NextToken
+func (i *Item) NextToken(token string) *Item {
+	i.statement.nextToken = token
+	return i
+}
+
+// Execute runs the PartiQL statement with ExecuteStatement, following NextToken until all pages are read
+// or, if Limit was called, until the limit is reached. In that case the token to continue the read
+// is set in the NextToken field of the output.
+// Retrieved items can be accessed using the Unmarshal() method.
+//
+// Example:
+//
+//	var data []dataItem
+//	err := db.
+//		Statement(`SELECT * FROM "test-table-1" WHERE "_partition_key" = ? AND begins_with("_sort_key", ?)`, "room#1", "2023").
+//		Limit(10).
+//		Execute(context.Background()).
+//		Unmarshal(&data, []string{"room"}).
+//		Run()
+func (i *Item) Execute(ctx context.Context) *output {
+	result := newOutput(i, ctx)
+	if i.err != nil {
+		return result
+	}
+
+	input := dynamodb.ExecuteStatementInput{
+		Statement:  aws.String(i.statement.text),
+		Parameters: i.statement.parameters,
+	}
+	if i.statement.nextToken != "" {
+		input.NextToken = aws.String(i.statement.nextToken)
+	}
+	for {
+		if i.pagination.limit > 0 {
+			input.Limit = aws.Int32(i.pagination.limit - int32(len(result.Results)))
+		}
+		out, err := i.c.client.ExecuteStatement(ctx, &input)
+		if err != nil {
+			result.item.err = i.opError(opStatement, err)
+			return result
+		}
+		if err := i.c.decodeItems(ctx, out.Items); err != nil {
+			result.item.err = dynamoError().method(opStatement).wrap(err)
+			return result
+		}
+		result.Results = append(result.Results, out.Items...)
+		if out.NextToken == nil {
+			return result
+		}
+		if i.pagination.limit > 0 && len(result.Results) >= int(i.pagination.limit) {
+			result.NextToken = *out.NextToken
+			return result
+		}
+		input.NextToken = out.NextToken
+	}
+}
+
+// AddBatchStatement adds the PartiQL statement to the batch held by newItem,
+// to be run with BatchStatement or TransactionStatement.
+//
+// Example:
+//
+//	batch := new(Item)
+//	for _, id := range ids {
+//		db.Statement(`UPDATE "test-table-1" SET "status" = ? WHERE "_partition_key" = ? AND "_sort_key" = ?`, "archived", id, "current").
+//			AddBatchStatement(batch)
+//	}
+//	results, err := batch.BatchStatement(context.Background(), 10)
+func (i *Item) AddBatchStatement(newItem *Item) {
+	if i.err != nil {
+		newItem.err = i.err
+		return
+	}
+	newItem.c = i.c
+	newItem.batchData.statements = append(newItem.batchData.statements, types.BatchStatementRequest{
+		Statement:  aws.String(i.statement.text),
+		Parameters: i.statement.parameters,
+	})
+}
+
+// BatchStatement runs the batched PartiQL statements with BatchExecuteStatement,
+// in groups of 25 statements sent in parallel using threadCount goroutines.
+// It returns one result per statement, in the order the statements were added.
+// A statement that fails doesn't fail the call, its error is reported in the Err field of its result.
+//
+// Example:
+//
+//	batch := new(Item)
+//	for _, id := range ids {
+//		db.Statement(`SELECT * FROM "test-table-1" WHERE "_partition_key" = ? AND "_sort_key" = ?`, id, "current").
+//			AddBatchStatement(batch)
+//	}
+//	results, err := batch.BatchStatement(context.Background(), 10)
+//	for _, result := range results {
+//		if result.Err != nil {
+//			...
+//		}
+//	}
+func (i *Item) BatchStatement(ctx context.Context, threadCount int) ([]StatementResult, error) {
+	if i.err != nil {
+		return nil, i.err
+	}
+	if len(i.batchData.statements) == 0 || i.c == nil {
+		return nil, validationError().method(opBatchStatement).message("no statement added with AddBatchStatement")
+	}
+	statements := i.batchData.statements
+	results := make([]StatementResult, len(statements))
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(threadCount)
+	var mu sync.Mutex
+	for start := 0; start < len(statements); start += statementBatchSize {
+		start := start
+		end := start + statementBatchSize
+		if end > len(statements) {
+			end = len(statements)
+		}
+		g.Go(func() error {
+			out, err := i.c.client.BatchExecuteStatement(ctx, &dynamodb.BatchExecuteStatementInput{
+				Statements: statements[start:end],
+			})
+			if err != nil {
+				return i.opError(opBatchStatement, err)
+			}
+			for n, response := range out.Responses {
+				result := StatementResult{Item: response.Item}
+				if response.Error != nil {
+					result.Err = dynamoError().
+						method(opBatchStatement).
+						kind(errorCodeKind(string(response.Error.Code))).
+						message(fmt.Sprintf("%s: %s", response.Error.Code, aws.ToString(response.Error.Message)))
+				} else if err := i.c.decodeItem(ctx, result.Item); err != nil {
+					return dynamoError().method(opBatchStatement).wrap(err)
+				}
+				mu.Lock()
+				results[start+n] = result
+				mu.Unlock()
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// TransactionStatement runs the batched PartiQL statements, at most 100, in a single transaction with ExecuteTransaction.
+// Either all statements succeed or none is applied.
+// It returns the items read by the statements, in the order the statements were added.
+//
+// Example:
+//
+//	tx := new(Item)
+//	db.Statement(`INSERT INTO "test-table-1" VALUE {'_partition_key': ?, '_sort_key': ?}`, "room#1", "current").AddBatchStatement(tx)
+//	db.Statement(`DELETE FROM "test-table-1" WHERE "_partition_key" = ? AND "_sort_key" = ?`, "room#2", "current").AddBatchStatement(tx)
+//	_, err := tx.TransactionStatement(context.Background())
+func (i *Item) TransactionStatement(ctx context.Context) ([]map[string]types.AttributeValue, error) {
+	if i.err != nil {
+		return nil, i.err
+	}
+	if len(i.batchData.statements) == 0 || i.c == nil {
+		return nil, validationError().method(opTransactionStatement).message("no statement added with AddBatchStatement")
+	}
+	if len(i.batchData.statements) > statementTransactionSize {
+		return nil, validationError().method(opTransactionStatement).message(fmt.Sprintf("a transaction holds at most %d statements, got %d", statementTransactionSize, len(i.batchData.statements)))
+	}
+
+	transaction := make([]types.ParameterizedStatement, len(i.batchData.statements))
+	for n, s := range i.batchData.statements {
+		transaction[n] = types.ParameterizedStatement{Statement: s.Statement, Parameters: s.Parameters}
+	}
+	out, err := i.c.client.ExecuteTransaction(ctx, &dynamodb.ExecuteTransactionInput{
+		TransactStatements: transaction,
+	})
+	if err != nil {
+		return nil, i.opError(opTransactionStatement, err)
+	}
+
+	items := make([]map[string]types.AttributeValue, len(out.Responses))
+	for n, response := range out.Responses {
+		items[n] = response.Item
+	}
+	if err := i.c.decodeItems(ctx, items); err != nil {
+		return nil, dynamoError().method(opTransactionStatement).wrap(err)
+	}
+	return items, nil
+}
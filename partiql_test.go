@@ -0,0 +1,185 @@
+package dygo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_statement_execute(t *testing.T) {
+	fake := newFakeDynamoDB()
+	var parameters []json.RawMessage
+	fake.hook = func(op string, req map[string]json.RawMessage) (int, any) {
+		if op != "ExecuteStatement" {
+			return 0, nil
+		}
+		json.Unmarshal(req["Parameters"], &parameters)
+		var token string
+		json.Unmarshal(req["NextToken"], &token)
+		var limit int
+		json.Unmarshal(req["Limit"], &limit)
+		item := func(id string) map[string]any {
+			return encodeFakeItem(map[string]types.AttributeValue{
+				"id":                 &types.AttributeValueMemberS{Value: id},
+				"custom_entity_type": &types.AttributeValueMemberS{Value: "room"},
+			})
+		}
+		if token == "" && limit == 1 {
+			return http.StatusOK, map[string]any{"Items": []any{item("1")}, "NextToken": "page-1"}
+		}
+		if token == "page-1" {
+			return http.StatusOK, map[string]any{"Items": []any{item("2")}, "NextToken": "page-2"}
+		}
+		if token == "" {
+			return http.StatusOK, map[string]any{"Items": []any{item("1"), item("2")}, "NextToken": "page-2"}
+		}
+		return http.StatusOK, map[string]any{"Items": []any{item("3")}}
+	}
+	c := newFakeClient(t, fake)
+
+	var data mockDataItems
+	err := c.Statement(`SELECT * FROM "test-table-1" WHERE "_partition_key" = ? AND "capacity" > ?`, "room#1", 10).
+		Execute(context.Background()).
+		WithCustomEntityTypeAttribute("custom_entity_type").
+		Unmarshal(&data, []string{"room"}).
+		Run()
+	assert.NoError(t, err)
+	assert.Len(t, data, 3)
+	assert.Equal(t, 2, fake.callCount("ExecuteStatement"))
+	assert.Len(t, parameters, 2)
+	assert.JSONEq(t, `{"N":"10"}`, string(parameters[1]))
+
+	data = nil
+	out := c.Statement(`SELECT * FROM "test-table-1"`).
+		Limit(1).
+		Execute(context.Background())
+	err = out.WithCustomEntityTypeAttribute("custom_entity_type").
+		Unmarshal(&data, []string{"room"}).
+		Run()
+	assert.NoError(t, err)
+	assert.Len(t, data, 1)
+	assert.Equal(t, "page-1", out.NextToken)
+	assert.Equal(t, 3, fake.callCount("ExecuteStatement"))
+
+	out = c.Statement(`SELECT * FROM "test-table-1"`).
+		Limit(1).
+		NextToken(out.NextToken).
+		Execute(context.Background())
+	assert.NoError(t, out.Run())
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "2"}, out.Results[0]["id"])
+	assert.Equal(t, "page-2", out.NextToken)
+
+	err = c.Statement("").Execute(context.Background()).Run()
+	assert.True(t, errors.Is(err, ErrValidation), err)
+	err = c.Statement("SELECT * FROM t WHERE a = ?", map[[2]int]int{{1, 2}: 1}).Execute(context.Background()).Run()
+	assert.Error(t, err)
+}
+
+func Test_batch_statement(t *testing.T) {
+	fake := newFakeDynamoDB()
+	fake.hook = func(op string, req map[string]json.RawMessage) (int, any) {
+		var statements []struct {
+			Statement  string
+			Parameters []map[string]string
+		}
+		json.Unmarshal(req["Statements"], &statements)
+		responses := []any{}
+		for _, s := range statements {
+			id := s.Parameters[0]["S"]
+			if id == "missing" {
+				responses = append(responses, map[string]any{"Error": map[string]string{"Code": "ValidationError", "Message": "invalid key"}})
+				continue
+			}
+			responses = append(responses, map[string]any{"Item": encodeFakeItem(map[string]types.AttributeValue{
+				"id": &types.AttributeValueMemberS{Value: id},
+			})})
+		}
+		return http.StatusOK, map[string]any{"Responses": responses}
+	}
+	c := newFakeClient(t, fake)
+
+	batch := new(Item)
+	for n := 0; n < 30; n++ {
+		id := fmt.Sprint(n)
+		if n == 27 {
+			id = "missing"
+		}
+		c.Statement(`SELECT * FROM "test-table-1" WHERE "id" = ?`, id).AddBatchStatement(batch)
+	}
+	results, err := batch.BatchStatement(context.Background(), 2)
+	assert.NoError(t, err)
+	assert.Len(t, results, 30)
+	assert.Equal(t, 2, fake.callCount("BatchExecuteStatement"))
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "26"}, results[26].Item["id"])
+	assert.True(t, errors.Is(results[27].Err, ErrValidation), results[27].Err)
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "29"}, results[29].Item["id"])
+
+	_, err = new(Item).BatchStatement(context.Background(), 2)
+	assert.True(t, errors.Is(err, ErrValidation), err)
+}
+
+func Test_transaction_statement(t *testing.T) {
+	fake := newFakeDynamoDB()
+	var statements []json.RawMessage
+	fake.hook = func(op string, req map[string]json.RawMessage) (int, any) {
+		json.Unmarshal(req["TransactStatements"], &statements)
+		if len(statements) == 1 {
+			return http.StatusBadRequest, map[string]any{
+				"__type":  "com.amazonaws.dynamodb.v20120810#TransactionCanceledException",
+				"message": "Transaction cancelled",
+				"CancellationReasons": []map[string]string{
+					{"Code": "ConditionalCheckFailed", "Message": "The conditional request failed"},
+				},
+			}
+		}
+		return http.StatusOK, map[string]any{"Responses": []any{map[string]any{}, map[string]any{}}}
+	}
+	c := newFakeClient(t, fake)
+
+	tx := new(Item)
+	c.Statement(`INSERT INTO "test-table-1" VALUE {'_partition_key': ?, '_sort_key': ?}`, "room#1", "current").AddBatchStatement(tx)
+	c.Statement(`DELETE FROM "test-table-1" WHERE "_partition_key" = ? AND "_sort_key" = ?`, "room#2", "current").AddBatchStatement(tx)
+	items, err := tx.TransactionStatement(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, items, 2)
+	assert.Len(t, statements, 2)
+
+	tx = new(Item)
+	c.Statement(`DELETE FROM "test-table-1" WHERE "_partition_key" = ?`, "room#2").AddBatchStatement(tx)
+	_, err = tx.TransactionStatement(context.Background())
+	assert.True(t, errors.Is(err, ErrConditionFailed), err)
+
+	tx = new(Item)
+	for n := 0; n <= statementTransactionSize; n++ {
+		c.Statement(`DELETE FROM "test-table-1" WHERE "_partition_key" = ?`, n).AddBatchStatement(tx)
+	}
+	_, err = tx.TransactionStatement(context.Background())
+	assert.True(t, errors.Is(err, ErrValidation), err)
+
+	_, err = new(Item).TransactionStatement(context.Background())
+	assert.True(t, errors.Is(err, ErrValidation), err)
+}
+
+func Test_statement_write_rejected_with_transforms(t *testing.T) {
+	fake := newFakeDynamoDB()
+	c := newFakeClient(t, fake)
+	c.cache = NewLRUCache(10, time.Minute)
+
+	assert.NoError(t, c.Statement(`SELECT * FROM "test-table-1" WHERE "_partition_key" = ?`, "room#1").err)
+
+	err := c.Statement(` update "test-table-1" SET "status" = ? WHERE "_partition_key" = ?`, "archived", "room#1").Execute(context.Background()).Run()
+	assert.True(t, errors.Is(err, ErrValidation), err)
+
+	tx := new(Item)
+	c.Statement(`DELETE FROM "test-table-1" WHERE "_partition_key" = ?`, "room#1").AddBatchStatement(tx)
+	_, err = tx.TransactionStatement(context.Background())
+	assert.True(t, errors.Is(err, ErrValidation), err)
+	assert.Equal(t, 0, fake.callCount("ExecuteTransaction"))
+}
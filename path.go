@@ -0,0 +1,249 @@
+package dygo
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// AttributeType is a DynamoDB attribute type, as checked by KeyAttributeType.
+type AttributeType = expression.DynamoDBAttributeType
+
+// The DynamoDB attribute types.
+const (
+	AttributeTypeString    AttributeType = expression.String
+	AttributeTypeNumber    AttributeType = expression.Number
+	AttributeTypeBinary    AttributeType = expression.Binary
+	AttributeTypeBool      AttributeType = expression.Boolean
+	AttributeTypeNull      AttributeType = expression.Null
+	AttributeTypeList      AttributeType = expression.List
+	AttributeTypeMap       AttributeType = expression.Map
+	AttributeTypeStringSet AttributeType = expression.StringSet
+	AttributeTypeNumberSet AttributeType = expression.NumberSet
+	AttributeTypeBinarySet AttributeType = expression.BinarySet
+)
+
+// Attribute paths name top-level or nested attributes wherever an attribute name is accepted:
+// in Project, Filter, AndFilter, OrFilter and Condition.
+// A path is a list of map keys separated by dots, each optionally followed by a list index:
+//
+//	address.city
+//	tags[0]
+//	rooms[2].beds[0].size
+//
+// A backslash escapes the next character, so attribute names containing dots or backslashes
+// can be written as in status\.v2, or built with EscapeName.
+
+// EscapeName escapes the dots, brackets and backslashes of an attribute name,
+// so that it is read as a single attribute and not as a path.
+//
+// Example:
+//
+//	db.InitScan().Project(dygo.EscapeName("status.v2"), "address.city")
+func EscapeName(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if r == '.' || r == '[' || r == ']' || r == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// pathSegment is a map key of an attribute path, with the list index that follows it.
+type pathSegment struct {
+	name    string
+	indexes []int
+}
+
+// parsePath splits an attribute path into its segments, see EscapeName for the syntax.
+func parsePath(path string) ([]pathSegment, error) {
+	if path == "" {
+		return nil, errors.New("attribute path is empty")
+	}
+	var segments []pathSegment
+	var current pathSegment
+	var name strings.Builder
+	escaped := false
+	afterIndex := false
+
+	endSegment := func(pos int) error {
+		current.name = name.String()
+		if current.name == "" {
+			return fmt.Errorf("invalid attribute path %q: empty name at position %d", path, pos+1)
+		}
+		if len(current.indexes) > 0 && strings.ContainsAny(current.name, "[]") {
+			return fmt.Errorf("invalid attribute path %q: a name containing brackets can't be indexed", path)
+		}
+		if strings.HasSuffix(current.name, "]") {
+			return fmt.Errorf("invalid attribute path %q: a name can't end with ]", path)
+		}
+		segments = append(segments, current)
+		current = pathSegment{}
+		name.Reset()
+		afterIndex = false
+		return nil
+	}
+
+	for pos := 0; pos < len(path); pos++ {
+		c := path[pos]
+		if escaped {
+			name.WriteByte(c)
+			escaped = false
+			continue
+		}
+		switch {
+		case c == '.':
+			if err := endSegment(pos); err != nil {
+				return nil, err
+			}
+		case c == '[':
+			end := strings.IndexByte(path[pos:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid attribute path %q: missing ] at position %d", path, pos+1)
+			}
+			index, err := strconv.Atoi(path[pos+1 : pos+end])
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid attribute path %q: invalid list index at position %d", path, pos+2)
+			}
+			if name.Len() == 0 {
+				return nil, fmt.Errorf("invalid attribute path %q: list index without a name at position %d", path, pos+1)
+			}
+			if len(current.indexes) > 0 {
+				// the expression package accepts a single list index per name
+				return nil, fmt.Errorf("invalid attribute path %q: nested list indexes are not supported", path)
+			}
+			current.indexes = append(current.indexes, index)
+			afterIndex = true
+			pos += end
+		case c == ']':
+			return nil, fmt.Errorf("invalid attribute path %q: unexpected ] at position %d", path, pos+1)
+		case afterIndex:
+			return nil, fmt.Errorf("invalid attribute path %q: expected . or [ at position %d", path, pos+1)
+		case c == '\\':
+			escaped = true
+		default:
+			name.WriteByte(c)
+		}
+	}
+	if escaped {
+		return nil, fmt.Errorf("invalid attribute path %q: trailing backslash", path)
+	}
+	if err := endSegment(len(path)); err != nil {
+		return nil, err
+	}
+	return segments, nil
+}
+
+// attributePath returns the name builder of an attribute path.
+func attributePath(path string) (expression.NameBuilder, error) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return expression.NameBuilder{}, err
+	}
+	var nb expression.NameBuilder
+	for n, segment := range segments {
+		var b strings.Builder
+		b.WriteString(segment.name)
+		for _, index := range segment.indexes {
+			fmt.Fprintf(&b, "[%d]", index)
+		}
+		if n == 0 {
+			nb = expression.NameNoDotSplit(b.String())
+			continue
+		}
+		nb = nb.AppendName(expression.NameNoDotSplit(b.String()))
+	}
+	return nb, nil
+}
+
+// pathName returns the name builder of an attribute path for the FilterFuncs and ConditionFuncs.
+// An invalid path gives an unset name builder, which fails when the expression is built;
+// Filter and Condition report the path error itself beforehand.
+func pathName(path string) expression.NameBuilder {
+	nb, _ := attributePath(path)
+	return nb
+}
+
+// containsMarker prefixes the placeholder string value of a contains condition on a non-string value,
+// since the expression package only builds contains conditions on strings.
+// The placeholder holds the DynamoDB JSON of the value and is replaced by the value in buildExpression.
+// The marker is random per process, so a string value of a condition can't be taken for a placeholder.
+var containsMarker = newContainsMarker()
+
+func newContainsMarker() string {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		panic(err)
+	}
+	return "\x00dygo-contains:" + hex.EncodeToString(nonce) + ":"
+}
+
+// containsCondition returns the contains condition on the attribute. If the value can't be marshalled,
+// the condition compares the attribute with a valueError, so the error is returned when the condition is built.
+func containsCondition(keyName string, value any) expression.ConditionBuilder {
+	operand, err := containsValue(value)
+	if err != nil {
+		return pathName(keyName).Equal(expression.Value(valueError{fmt.Errorf("contains value of attribute %q: %w", keyName, err)}))
+	}
+	return pathName(keyName).Contains(operand)
+}
+
+// containsValue returns the operand of a contains condition: the value itself if it is a string,
+// else a placeholder restored by buildExpression.
+func containsValue(value any) (string, error) {
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	av, ok := value.(types.AttributeValue)
+	if !ok {
+		var err error
+		if av, err = attributevalue.Marshal(value); err != nil {
+			return "", err
+		}
+	}
+	if s, ok := av.(*types.AttributeValueMemberS); ok {
+		return s.Value, nil
+	}
+	data, err := marshalDynamoDBJSON(av)
+	if err != nil {
+		return "", err
+	}
+	return containsMarker + string(data), nil
+}
+
+// valueError is the value of a condition whose operand can't be computed, such as a BlindEqual filter
+// without blind index key or a contains condition on a value that can't be marshalled.
+// Its marshalling fails, so the error is returned when the condition is built.
+type valueError struct {
+	err error
+}
+
+// MarshalDynamoDBAttributeValue returns the error of the operand.
+func (e valueError) MarshalDynamoDBAttributeValue() (types.AttributeValue, error) {
+	return nil, e.err
+}
+
+// restoreContainsValues replaces, in place, the placeholders left by containsValue in the values of a built expression.
+func restoreContainsValues(values map[string]types.AttributeValue) error {
+	for name, v := range values {
+		s, ok := v.(*types.AttributeValueMemberS)
+		if !ok || !strings.HasPrefix(s.Value, containsMarker) {
+			continue
+		}
+		av, err := unmarshalDynamoDBJSON([]byte(strings.TrimPrefix(s.Value, containsMarker)))
+		if err != nil {
+			return err
+		}
+		values[name] = av
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package dygo
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_attribute_path(t *testing.T) {
+	tests := []struct {
+		path  string
+		expr  string
+		names []string
+	}{
+		{path: "name", expr: "#0", names: []string{"name"}},
+		{path: "address.city", expr: "#0.#1", names: []string{"address", "city"}},
+		{path: "tags[0]", expr: "#0[0]", names: []string{"tags"}},
+		{path: "rooms[2].beds[0].size", expr: "#0[2].#1[0].#2", names: []string{"rooms", "beds", "size"}},
+		{path: `status\.v2.value`, expr: "#0.#1", names: []string{"status.v2", "value"}},
+		{path: EscapeName(`a.b\c`), expr: "#0", names: []string{`a.b\c`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			name, err := attributePath(tt.path)
+			if err != nil {
+				t.Fatalf("unexpected error : %v", err)
+			}
+			expr, err := expression.NewBuilder().WithProjection(expression.NamesList(name)).Build()
+			if err != nil {
+				t.Fatalf("unexpected error : %v", err)
+			}
+			assert.Equal(t, tt.expr, *expr.Projection())
+			for n, want := range tt.names {
+				assert.Equal(t, want, expr.Names()["#"+string(rune('0'+n))])
+			}
+		})
+	}
+
+	for _, path := range []string{"", "a..b", ".a", "a.", "tags[x]", "tags[0", "[0]", "tags[0]x", "tags[0][1]", "a]b", `a\`} {
+		_, err := parsePath(path)
+		assert.Error(t, err, path)
+	}
+}
+
+func Test_path_in_project_and_filter(t *testing.T) {
+	c := &Client{tableName: "test-table-1", partitionKey: "_partition_key"}
+	i := c.InitScan().
+		Project("_partition_key", "address.city", EscapeName("a,b")).
+		Filter("tags[0]", KeyEqual("large")).
+		AndFilter("rooms", KeySizeGreaterThan(2)).
+		AndFilter("address", KeyAttributeType(AttributeTypeMap))
+	assert.NoError(t, i.err)
+	expr, err := i.getScanExpression()
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	assert.Equal(t, "#3, #2.#4, #5", *expr.Projection())
+	assert.Equal(t, "a,b", expr.Names()["#5"])
+	assert.Equal(t, "((#0[0] = :0) AND (size (#1) > :1)) AND (attribute_type (#2, :2))", *expr.Filter())
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "M"}, expr.Values()[":2"])
+
+	assert.Error(t, c.InitScan().Project("address..city").err)
+	assert.Error(t, c.InitScan().Filter("tags[", KeyEqual(1)).err)
+	assert.Error(t, c.Item(nil).Condition("a]", ConditionEqual(1)).err)
+}
+
+func Test_contains_on_sets(t *testing.T) {
+	c := &Client{tableName: "test-table-1", partitionKey: "_partition_key"}
+	i := c.InitScan().
+		Filter("floors", KeyContains(3)).
+		AndFilter("name", KeyContains("hall")).
+		AndFilter("blobs", KeyNotContains([]byte{1, 2}))
+	expr, err := i.getScanExpression()
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	assert.Equal(t, "((contains (#0, :0)) AND (contains (#1, :1))) AND (NOT (contains (#2, :2)))", *expr.Filter())
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "3"}, expr.Values()[":0"])
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "hall"}, expr.Values()[":1"])
+	assert.Equal(t, &types.AttributeValueMemberB{Value: []byte{1, 2}}, expr.Values()[":2"])
+
+	i = c.InitScan().FilterString(`address.city = 'Tokyo' AND floors contains 3 AND status\.v2 exists`)
+	assert.NoError(t, i.err)
+	expr, err = i.getScanExpression()
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	assert.Equal(t, "((#0.#1 = :0) AND (contains (#2, :1))) AND (attribute_exists (#3))", *expr.Filter())
+	assert.Equal(t, "status.v2", expr.Names()["#3"])
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "3"}, expr.Values()[":1"])
+
+	forged := "\x00dygo-contains:" + `{"N":"3"}`
+	expr, err = c.InitScan().Filter("name", KeyContains(forged)).getScanExpression()
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	assert.Equal(t, &types.AttributeValueMemberS{Value: forged}, expr.Values()[":0"])
+
+	// values that can't be marshalled fail the expression instead of matching every item
+	_, err = c.InitScan().Filter("floors", KeyContains(make(chan int))).getScanExpression()
+	assert.Error(t, err)
+	_, err = c.InitScan().Filter("floors", KeyNotContains(make(chan int))).getScanExpression()
+	assert.Error(t, err)
+}
+
+func Test_in_on_escaped_path(t *testing.T) {
+	c := &Client{tableName: "test-table-1", partitionKey: "_partition_key"}
+	i := c.InitScan().FilterString(`status\.v2 IN ('a', 'b') AND address.city IN ('Tokyo')`)
+	assert.NoError(t, i.err)
+	expr, err := i.getScanExpression()
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	assert.Equal(t, "(#0 IN (:0, :1)) AND (#1.#2 IN (:2))", *expr.Filter())
+	assert.Equal(t, "status.v2", expr.Names()["#0"])
+	assert.Equal(t, "address", expr.Names()["#1"])
+	assert.Equal(t, "city", expr.Names()["#2"])
+}
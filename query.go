@@ -34,7 +34,7 @@ func (i *Item) Query(ctx context.Context) *output {
 
 	expr, err := i.getQueryExpression()
 	if err != nil {
-		result.item.err = dynamoError().method(opQuery).message(err.Error())
+		result.item.err = dynamoError().method(opQuery).wrap(err)
 	}
 
 	input := dynamodb.QueryInput{
@@ -76,10 +76,10 @@ func (i *Item) Query(ctx context.Context) *output {
 func (i *Item) querySinglePage(ctx context.Context, input *dynamodb.QueryInput, result *output) (*output, error) {
 	output, err := i.c.client.Query(ctx, input)
 	if err != nil {
-		if err := getDynamoDBError(opQuery, err); err != nil {
-			return nil, err
-		}
-		return nil, dynamoError().method(opQuery).message(err.Error())
+		return nil, i.opError(opQuery, err)
+	}
+	if err := i.c.decodeItems(ctx, output.Items); err != nil {
+		return nil, dynamoError().method(opQuery).wrap(err)
 	}
 	// fetch with pagination
 	if i.pagination.limit > 0 {
@@ -100,10 +100,10 @@ func (i *Item) queryAllPages(ctx context.Context, input *dynamodb.QueryInput, re
 	for paginator.HasMorePages() {
 		output, err := paginator.NextPage(ctx)
 		if err != nil {
-			if err := getDynamoDBError(opQuery, err); err != nil {
-				return nil, err
-			}
-			return nil, dynamoError().method(opQuery).message(err.Error())
+			return nil, i.opError(opQuery, err)
+		}
+		if err := i.c.decodeItems(ctx, output.Items); err != nil {
+			return nil, dynamoError().method(opQuery).wrap(err)
 		}
 		items = append(items, output.Items...)
 	}
@@ -41,7 +41,7 @@ func (i *Item) Scan(ctx context.Context) *output {
 
 	expr, err := i.getScanExpression()
 	if err != nil {
-		result.item.err = dynamoError().method(opScan).message(err.Error())
+		result.item.err = dynamoError().method(opScan).wrap(err)
 		return result
 	}
 
@@ -71,10 +71,10 @@ func (i *Item) scan(ctx context.Context, input *dynamodb.ScanInput, result *outp
 	for paginator.HasMorePages() {
 		output, err := paginator.NextPage(ctx)
 		if err != nil {
-			if err := getDynamoDBError(opScan, err); err != nil {
-				return nil, err
-			}
-			return nil, dynamoError().method(opScan).message(err.Error())
+			return nil, i.opError(opScan, err)
+		}
+		if err := i.c.decodeItems(ctx, output.Items); err != nil {
+			return nil, dynamoError().method(opScan).wrap(err)
 		}
 		items = append(items, output.Items...)
 		if i.pagination.limit > 0 && len(items) >= int(i.pagination.limit) {
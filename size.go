@@ -0,0 +1,187 @@
+package dygo
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	// maxItemSize is the maximum size of a single DynamoDB item.
+	maxItemSize = 400 * 1024
+	// maxBatchWriteSize is the maximum total size of a BatchWriteItem request.
+	maxBatchWriteSize = 16 * 1024 * 1024
+)
+
+// ErrItemTooLarge is returned, wrapped in an *ItemTooLargeError, when an item exceeds the DynamoDB item size limit.
+var ErrItemTooLarge = errors.New("item too large")
+
+// ItemTooLargeError reports an item that exceeds the DynamoDB item size limit before it is sent.
+// It matches ErrItemTooLarge and ErrValidation with errors.Is.
+type ItemTooLargeError struct {
+	// Key is the primary key of the offending item.
+	Key map[string]types.AttributeValue
+	// Attribute is the name of the largest attribute of the item.
+	Attribute string
+	// Size is the size of the item in bytes.
+	Size int
+	// Limit is the maximum size in bytes.
+	Limit int
+}
+
+// Error returns the error message of the ItemTooLargeError.
+func (e *ItemTooLargeError) Error() string {
+	return fmt.Sprintf("item {%s} is %d bytes which exceeds the limit of %d bytes, largest attribute is %q",
+		keyString(e.Key), e.Size, e.Limit, e.Attribute)
+}
+
+// Is reports whether target is ErrItemTooLarge or ErrValidation.
+func (e *ItemTooLargeError) Is(target error) bool {
+	return target == ErrItemTooLarge || target == ErrValidation
+}
+
+// ItemSize returns the size in bytes of the item, following the DynamoDB item size calculation rules.
+// It is the size counted against the 400KB item limit and the 16MB batch request limit.
+//
+// Example:
+//
+//	av, _ := attributevalue.MarshalMap(d)
+//	if dygo.ItemSize(av) > 300*1024 {
+//		// consider compressing some attributes
+//	}
+func ItemSize(item map[string]types.AttributeValue) int {
+	size := 0
+	for name, av := range item {
+		size += len(name) + attributeValueSize(av)
+	}
+	return size
+}
+
+// attributeValueSize returns the size in bytes of an attribute value, excluding its name.
+func attributeValueSize(av types.AttributeValue) int {
+	switch v := av.(type) {
+	case *types.AttributeValueMemberS:
+		return len(v.Value)
+	case *types.AttributeValueMemberN:
+		return numberSize(v.Value)
+	case *types.AttributeValueMemberB:
+		return len(v.Value)
+	case *types.AttributeValueMemberBOOL, *types.AttributeValueMemberNULL:
+		return 1
+	case *types.AttributeValueMemberSS:
+		size := 0
+		for _, s := range v.Value {
+			size += len(s)
+		}
+		return size
+	case *types.AttributeValueMemberNS:
+		size := 0
+		for _, n := range v.Value {
+			size += numberSize(n)
+		}
+		return size
+	case *types.AttributeValueMemberBS:
+		size := 0
+		for _, b := range v.Value {
+			size += len(b)
+		}
+		return size
+	case *types.AttributeValueMemberL:
+		// lists and maps have 3 bytes of overhead plus 1 byte per element
+		size := 3
+		for _, elem := range v.Value {
+			size += attributeValueSize(elem) + 1
+		}
+		return size
+	case *types.AttributeValueMemberM:
+		size := 3
+		for name, elem := range v.Value {
+			size += len(name) + attributeValueSize(elem) + 1
+		}
+		return size
+	}
+	return 0
+}
+
+// numberSize returns the size of a number, which is 1 byte per two significant digits plus 1 byte.
+func numberSize(n string) int {
+	size := 1
+	if strings.HasPrefix(n, "-") {
+		size++
+		n = n[1:]
+	}
+	n = strings.TrimPrefix(n, "+")
+	if i := strings.IndexAny(n, "eE"); i >= 0 {
+		n = n[:i]
+	}
+	n = strings.Replace(n, ".", "", 1)
+	n = strings.Trim(n, "0")
+	return size + (len(n)+1)/2
+}
+
+// largestAttribute returns the name of the largest attribute of the item.
+func largestAttribute(item map[string]types.AttributeValue) string {
+	largest, largestSize := "", -1
+	for name, av := range item {
+		if size := len(name) + attributeValueSize(av); size > largestSize {
+			largest, largestSize = name, size
+		}
+	}
+	return largest
+}
+
+// checkItemSize returns an *ItemTooLargeError if the item exceeds the DynamoDB item size limit.
+func (c *Client) checkItemSize(item map[string]types.AttributeValue) error {
+	size := ItemSize(item)
+	if size <= maxItemSize {
+		return nil
+	}
+	return &ItemTooLargeError{
+		Key:       c.primaryKey(item),
+		Attribute: largestAttribute(item),
+		Size:      size,
+		Limit:     maxItemSize,
+	}
+}
+
+// primaryKey extracts the primary key attributes of the table from the item.
+func (c *Client) primaryKey(item map[string]types.AttributeValue) map[string]types.AttributeValue {
+	key := make(map[string]types.AttributeValue)
+	if v, ok := item[c.partitionKey]; ok {
+		key[c.partitionKey] = v
+	}
+	if v, ok := item[c.sortKey]; ok && c.sortKey != "" {
+		key[c.sortKey] = v
+	}
+	return key
+}
+
+// keyString returns a stable, human readable representation of a key, e.g. "pk=rm-1, sk=current".
+func keyString(key map[string]types.AttributeValue) string {
+	names := make([]string, 0, len(key))
+	for name := range key {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		var value string
+		switch v := key[name].(type) {
+		case *types.AttributeValueMemberS:
+			value = v.Value
+		case *types.AttributeValueMemberN:
+			value = v.Value
+		case *types.AttributeValueMemberB:
+			value = base64.StdEncoding.EncodeToString(v.Value)
+		default:
+			value = fmt.Sprintf("%v", v)
+		}
+		parts = append(parts, name+"="+value)
+	}
+	return strings.Join(parts, ", ")
+}
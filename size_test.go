@@ -0,0 +1,102 @@
+package dygo
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type largeItem struct {
+	PK   string `dynamodbav:"_partition_key"`
+	SK   string `dynamodbav:"_sort_key"`
+	Body string `dynamodbav:"body"`
+}
+
+func (l largeItem) Validate() error {
+	return nil
+}
+
+func Test_item_size(t *testing.T) {
+	tests := []struct {
+		name string
+		item map[string]types.AttributeValue
+		size int
+	}{
+		{"string", map[string]types.AttributeValue{"name": &types.AttributeValueMemberS{Value: "hello"}}, 9},
+		{"number", map[string]types.AttributeValue{"n": &types.AttributeValueMemberN{Value: "12345"}}, 5},
+		{"negative decimal", map[string]types.AttributeValue{"n": &types.AttributeValueMemberN{Value: "-0.0120"}}, 4},
+		{"zero", map[string]types.AttributeValue{"n": &types.AttributeValueMemberN{Value: "0"}}, 2},
+		{"binary", map[string]types.AttributeValue{"b": &types.AttributeValueMemberB{Value: []byte{1, 2, 3}}}, 4},
+		{"bool and null", map[string]types.AttributeValue{
+			"t": &types.AttributeValueMemberBOOL{Value: true},
+			"u": &types.AttributeValueMemberNULL{Value: true},
+		}, 4},
+		{"string set", map[string]types.AttributeValue{"ss": &types.AttributeValueMemberSS{Value: []string{"ab", "cde"}}}, 7},
+		{"list", map[string]types.AttributeValue{"l": &types.AttributeValueMemberL{Value: []types.AttributeValue{
+			&types.AttributeValueMemberS{Value: "ab"},
+			&types.AttributeValueMemberBOOL{Value: false},
+		}}}, 9},
+		{"map", map[string]types.AttributeValue{"m": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+			"key": &types.AttributeValueMemberS{Value: "value"},
+		}}}, 13},
+		{"empty map", map[string]types.AttributeValue{"m": &types.AttributeValueMemberM{}}, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.size, ItemSize(tt.item))
+		})
+	}
+}
+
+func Test_create_item_too_large(t *testing.T) {
+	c := &Client{tableName: "test-table-1", partitionKey: "_partition_key", sortKey: "_sort_key"}
+	d := largeItem{PK: "doc-1", SK: "current", Body: strings.Repeat("x", maxItemSize)}
+
+	err := c.Item(d).Create(context.Background())
+	if !errors.Is(err, ErrItemTooLarge) {
+		t.Fatalf("expected ErrItemTooLarge, got : %v", err)
+	}
+	var tooLarge *ItemTooLargeError
+	if !errors.As(err, &tooLarge) {
+		t.Fatalf("expected *ItemTooLargeError, got : %T", err)
+	}
+	assert.Equal(t, "body", tooLarge.Attribute)
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "doc-1"}, tooLarge.Key["_partition_key"])
+	assert.Contains(t, err.Error(), "_partition_key=doc-1, _sort_key=current")
+
+	batch := new(Item)
+	c.Item(d).AddBatchUpsertItem(batch)
+	_, err = batch.BatchUpsertItemResult(context.Background(), 1)
+	assert.True(t, errors.Is(err, ErrItemTooLarge))
+}
+
+func Test_batch_upsert_respects_request_size(t *testing.T) {
+	c := &Client{tableName: "test-table-1", partitionKey: "_partition_key", sortKey: "_sort_key"}
+	batch := new(Item)
+	// every batch must stay within both the request count and the request size limits
+	for i := 0; i < 45; i++ {
+		d := largeItem{PK: "doc-" + strings.Repeat("1", i+1), SK: "current", Body: strings.Repeat("x", 390*1024)}
+		c.Item(d).AddBatchUpsertItem(batch)
+	}
+	batches, err := batch.putBatches(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+	total := 0
+	for _, b := range batches {
+		size := 0
+		for _, requests := range b {
+			total += len(requests)
+			for _, r := range requests {
+				size += ItemSize(r.PutRequest.Item)
+			}
+		}
+		assert.LessOrEqual(t, size, maxBatchWriteSize)
+	}
+	assert.Equal(t, 45, total)
+}
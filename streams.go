@@ -0,0 +1,503 @@
+package dygo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodbstreams"
+	streamtypes "github.com/aws/aws-sdk-go-v2/service/dynamodbstreams/types"
+	"golang.org/x/sync/errgroup"
+)
+
+const opStream = "Stream"
+
+const (
+	defaultStreamPollInterval      = time.Second
+	defaultStreamDiscoveryInterval = 10 * time.Second
+	defaultStreamRetryWait         = 100 * time.Millisecond
+	defaultStreamMaxRetry          = 3
+
+	// shardEnd is the checkpoint of a shard that was read to its end.
+	shardEnd = "SHARD_END"
+)
+
+// StreamsAPI is the subset of the DynamoDB Streams API used by StreamConsumer.
+// It is implemented by *dynamodbstreams.Client and can be faked in tests.
+type StreamsAPI interface {
+	DescribeStream(ctx context.Context, params *dynamodbstreams.DescribeStreamInput, optFns ...func(*dynamodbstreams.Options)) (*dynamodbstreams.DescribeStreamOutput, error)
+	GetShardIterator(ctx context.Context, params *dynamodbstreams.GetShardIteratorInput, optFns ...func(*dynamodbstreams.Options)) (*dynamodbstreams.GetShardIteratorOutput, error)
+	GetRecords(ctx context.Context, params *dynamodbstreams.GetRecordsInput, optFns ...func(*dynamodbstreams.Options)) (*dynamodbstreams.GetRecordsOutput, error)
+}
+
+// StreamEvent is a stream record decoded into the registered entity type T.
+// NewImage is nil for REMOVE events and OldImage is nil for INSERT events,
+// or whenever the stream view type doesn't include the image.
+type StreamEvent[T any] struct {
+	EventID                     string
+	EventName                   string
+	ShardID                     string
+	SequenceNumber              string
+	ApproximateCreationDateTime time.Time
+	Keys                        map[string]types.AttributeValue
+	NewImage                    *T
+	OldImage                    *T
+}
+
+// StreamConsumer reads the records of a DynamoDB stream, decodes the item images into the registered entity types
+// and passes them to typed handlers. Child shards are only read once their parent shard is read to its end,
+// so records of an item are handled in order.
+type StreamConsumer struct {
+	c                 *Client
+	api               StreamsAPI
+	streamArn         string
+	checkpoints       CheckpointStore
+	entityAttribute   string
+	startingPosition  streamtypes.ShardIteratorType
+	batchSize         int32
+	pollInterval      time.Duration
+	discoveryInterval time.Duration
+	maxRetry          int
+	retryWait         time.Duration
+	handlers          map[string]streamHandler
+}
+
+// StreamOption configures a StreamConsumer.
+type StreamOption func(*StreamConsumer)
+
+// streamHandler decodes the images of a record into a StreamEvent and handles it.
+type streamHandler struct {
+	decode func(r *streamRecord) (interface{}, error)
+	handle func(ctx context.Context, event interface{}) error
+}
+
+// streamRecord is a stream record with its images converted to DynamoDB attribute values.
+type streamRecord struct {
+	eventID        string
+	eventName      string
+	shardID        string
+	sequenceNumber string
+	created        time.Time
+	keys           map[string]types.AttributeValue
+	newImage       map[string]types.AttributeValue
+	oldImage       map[string]types.AttributeValue
+}
+
+// WithCheckpointStore sets where the consumer keeps its position in each shard. It defaults to a MemoryCheckpointStore.
+func WithCheckpointStore(store CheckpointStore) StreamOption {
+	return func(s *StreamConsumer) {
+		s.checkpoints = store
+	}
+}
+
+// WithStreamEntityAttribute sets the attribute holding the entity type of an item.
+// The part of its value before the key separator of the client selects the handler.
+// It defaults to the partition key of the first GSI, or the partition key of the table if there is no GSI.
+func WithStreamEntityAttribute(attr string) StreamOption {
+	return func(s *StreamConsumer) {
+		s.entityAttribute = attr
+	}
+}
+
+// WithStreamStartingPosition sets where the consumer starts reading shards without a checkpoint.
+// It defaults to TRIM_HORIZON.
+func WithStreamStartingPosition(position streamtypes.ShardIteratorType) StreamOption {
+	return func(s *StreamConsumer) {
+		s.startingPosition = position
+	}
+}
+
+// WithStreamBatchSize sets the maximum number of records read from a shard at once.
+func WithStreamBatchSize(size int32) StreamOption {
+	return func(s *StreamConsumer) {
+		s.batchSize = size
+	}
+}
+
+// WithStreamPollInterval sets how long the consumer waits before reading an open shard again when it had no new records.
+// It defaults to 1s.
+func WithStreamPollInterval(interval time.Duration) StreamOption {
+	return func(s *StreamConsumer) {
+		s.pollInterval = interval
+	}
+}
+
+// WithStreamDiscoveryInterval sets how often the consumer looks for new shards. It defaults to 10s.
+func WithStreamDiscoveryInterval(interval time.Duration) StreamOption {
+	return func(s *StreamConsumer) {
+		s.discoveryInterval = interval
+	}
+}
+
+// WithStreamRetry sets how often a failing handler is retried, and the wait before the first retry.
+// The wait doubles with every retry. It defaults to 3 retries starting at 100ms.
+func WithStreamRetry(count int, wait time.Duration) StreamOption {
+	return func(s *StreamConsumer) {
+		s.maxRetry = count
+		s.retryWait = wait
+	}
+}
+
+// NewStreamConsumer returns a consumer of the stream with the given ARN, reading records through api.
+//
+// Example:
+//
+//	consumer := db.NewStreamConsumer(dynamodbstreams.NewFromConfig(cfg), streamArn,
+//		WithCheckpointStore(NewDynamoDBCheckpointStore(db, "checkpoint#")),
+//	)
+//	RegisterStreamHandler(consumer, "room", func(ctx context.Context, e StreamEvent[dataItem]) error {
+//		log.Println(e.EventName, e.NewImage)
+//		return nil
+//	})
+//	err := consumer.Run(ctx)
+func (c *Client) NewStreamConsumer(api StreamsAPI, streamArn string, opts ...StreamOption) *StreamConsumer {
+	s := &StreamConsumer{
+		c:                 c,
+		api:               api,
+		streamArn:         streamArn,
+		checkpoints:       NewMemoryCheckpointStore(),
+		startingPosition:  streamtypes.ShardIteratorTypeTrimHorizon,
+		pollInterval:      defaultStreamPollInterval,
+		discoveryInterval: defaultStreamDiscoveryInterval,
+		maxRetry:          defaultStreamMaxRetry,
+		retryWait:         defaultStreamRetryWait,
+		handlers:          make(map[string]streamHandler),
+	}
+	if len(c.gsis) > 0 {
+		s.entityAttribute = c.gsis[0].partitionKey
+	} else {
+		s.entityAttribute = c.partitionKey
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// RegisterStreamHandler registers the handler for records of items of the given entity type.
+// The item images of the records are unmarshalled into T. Records of entity types without a handler are skipped.
+// Registering a handler for an entity type replaces the previous one. Handlers must not be registered while the consumer runs.
+func RegisterStreamHandler[T any](s *StreamConsumer, entityType string, handler func(ctx context.Context, event StreamEvent[T]) error) {
+	s.handlers[entityType] = streamHandler{
+		decode: func(r *streamRecord) (interface{}, error) {
+			event := StreamEvent[T]{
+				EventID:                     r.eventID,
+				EventName:                   r.eventName,
+				ShardID:                     r.shardID,
+				SequenceNumber:              r.sequenceNumber,
+				ApproximateCreationDateTime: r.created,
+				Keys:                        r.keys,
+			}
+			if r.newImage != nil {
+				event.NewImage = new(T)
+				if err := attributevalue.UnmarshalMap(r.newImage, event.NewImage); err != nil {
+					return nil, err
+				}
+			}
+			if r.oldImage != nil {
+				event.OldImage = new(T)
+				if err := attributevalue.UnmarshalMap(r.oldImage, event.OldImage); err != nil {
+					return nil, err
+				}
+			}
+			return event, nil
+		},
+		handle: func(ctx context.Context, event interface{}) error {
+			return handler(ctx, event.(StreamEvent[T]))
+		},
+	}
+}
+
+// Run reads all shards of the stream until ctx is done or a handler fails after all retries.
+// Shards are read in parallel, respecting their lineage, and the position in each shard is checkpointed
+// after every batch of records.
+func (s *StreamConsumer) Run(ctx context.Context) error {
+	g, ctx := errgroup.WithContext(ctx)
+	wake := make(chan struct{}, 1)
+
+	var mu sync.Mutex
+	started := make(map[string]bool)
+	finished := make(map[string]bool)
+	isFinished := func(shardID string) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return finished[shardID]
+	}
+
+	g.Go(func() error {
+		for {
+			shards, err := s.describeShards(ctx)
+			if err != nil {
+				return err
+			}
+			known := make(map[string]bool, len(shards))
+			for _, shard := range shards {
+				known[aws.ToString(shard.ShardId)] = true
+			}
+
+			// finished shards found through their checkpoint may unblock their children, so repeat until nothing changes
+			for progress := true; progress; {
+				progress = false
+				for _, shard := range shards {
+					shardID := aws.ToString(shard.ShardId)
+					parentID := aws.ToString(shard.ParentShardId)
+					if started[shardID] || (parentID != "" && known[parentID] && !isFinished(parentID)) {
+						continue
+					}
+
+					checkpoint, err := s.checkpoints.GetCheckpoint(ctx, s.streamArn, shardID)
+					if err != nil {
+						return fmt.Errorf("failed to read checkpoint of shard %s: %w", shardID, err)
+					}
+					started[shardID] = true
+					if checkpoint == shardEnd {
+						mu.Lock()
+						finished[shardID] = true
+						mu.Unlock()
+						progress = true
+						continue
+					}
+
+					g.Go(func() error {
+						if err := s.readShard(ctx, shardID, checkpoint); err != nil {
+							return err
+						}
+						mu.Lock()
+						finished[shardID] = true
+						mu.Unlock()
+						select {
+						case wake <- struct{}{}:
+						default:
+						}
+						return nil
+					})
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-wake:
+			case <-time.After(s.discoveryInterval):
+			}
+		}
+	})
+
+	if err := g.Wait(); err != nil {
+		return dynamoError().method(opStream).wrap(err)
+	}
+	return nil
+}
+
+// describeShards returns all shards of the stream.
+func (s *StreamConsumer) describeShards(ctx context.Context) ([]streamtypes.Shard, error) {
+	var shards []streamtypes.Shard
+	input := &dynamodbstreams.DescribeStreamInput{StreamArn: aws.String(s.streamArn)}
+	for {
+		out, err := s.api.DescribeStream(ctx, input, s.c.streamsOptions)
+		if err != nil {
+			return nil, err
+		}
+		if out.StreamDescription == nil {
+			return shards, nil
+		}
+		shards = append(shards, out.StreamDescription.Shards...)
+		if out.StreamDescription.LastEvaluatedShardId == nil {
+			return shards, nil
+		}
+		input.ExclusiveStartShardId = out.StreamDescription.LastEvaluatedShardId
+	}
+}
+
+// shardIterator returns an iterator positioned after the checkpoint, or at the starting position if there is none.
+func (s *StreamConsumer) shardIterator(ctx context.Context, shardID, checkpoint string) (*string, error) {
+	input := &dynamodbstreams.GetShardIteratorInput{
+		StreamArn:         aws.String(s.streamArn),
+		ShardId:           aws.String(shardID),
+		ShardIteratorType: s.startingPosition,
+	}
+	if checkpoint != "" {
+		input.ShardIteratorType = streamtypes.ShardIteratorTypeAfterSequenceNumber
+		input.SequenceNumber = aws.String(checkpoint)
+	}
+	out, err := s.api.GetShardIterator(ctx, input, s.c.streamsOptions)
+	if err != nil {
+		return nil, err
+	}
+	return out.ShardIterator, nil
+}
+
+// readShard handles the records of the shard after the checkpoint until the shard is closed and read to its end.
+func (s *StreamConsumer) readShard(ctx context.Context, shardID, checkpoint string) error {
+	iterator, err := s.shardIterator(ctx, shardID, checkpoint)
+	if err != nil {
+		return fmt.Errorf("shard %s: %w", shardID, err)
+	}
+
+	for iterator != nil {
+		input := &dynamodbstreams.GetRecordsInput{ShardIterator: iterator}
+		if s.batchSize > 0 {
+			input.Limit = aws.Int32(s.batchSize)
+		}
+		out, err := s.api.GetRecords(ctx, input, s.c.streamsOptions)
+		var expired *streamtypes.ExpiredIteratorException
+		if errors.As(err, &expired) {
+			if iterator, err = s.shardIterator(ctx, shardID, checkpoint); err != nil {
+				return fmt.Errorf("shard %s: %w", shardID, err)
+			}
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("shard %s: %w", shardID, err)
+		}
+
+		// a batch holding only the records of checkpoints written to the streamed table isn't checkpointed,
+		// since the checkpoint would add a record to read at the next poll, forever
+		handled, checkpointed := checkpoint, true
+		for _, r := range out.Records {
+			if err := s.handleRecord(ctx, shardID, r); err != nil {
+				err = fmt.Errorf("shard %s: %w", shardID, err)
+				if handled != checkpoint && !checkpointed {
+					// keep the progress made before the failing record
+					if cpErr := s.checkpoints.SetCheckpoint(ctx, s.streamArn, shardID, handled); cpErr != nil {
+						err = errors.Join(err, fmt.Errorf("shard %s: failed to write checkpoint: %w", shardID, cpErr))
+					}
+				}
+				return err
+			}
+			handled = aws.ToString(r.Dynamodb.SequenceNumber)
+			checkpointed = checkpointed && s.isCheckpointRecord(r)
+		}
+		if handled != checkpoint && !checkpointed {
+			if err := s.checkpoints.SetCheckpoint(ctx, s.streamArn, shardID, handled); err != nil {
+				return fmt.Errorf("shard %s: failed to write checkpoint: %w", shardID, err)
+			}
+			checkpoint = handled
+		}
+
+		iterator = out.NextShardIterator
+		if iterator != nil && len(out.Records) == 0 {
+			if err := sleepContext(ctx, s.pollInterval); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := s.checkpoints.SetCheckpoint(ctx, s.streamArn, shardID, shardEnd); err != nil {
+		return fmt.Errorf("shard %s: failed to write checkpoint: %w", shardID, err)
+	}
+	return nil
+}
+
+// isCheckpointRecord reports whether the record is the write of a checkpoint of the consumer,
+// made by a CheckpointStore writing to the streamed table.
+func (s *StreamConsumer) isCheckpointRecord(r streamtypes.Record) bool {
+	store, ok := s.checkpoints.(checkpointRecordStore)
+	return ok && r.Dynamodb != nil && store.isCheckpointItem(s.streamArn, fromStreamItem(r.Dynamodb.Keys))
+}
+
+// handleRecord decodes the record and passes it to the handler of its entity type, retrying failed attempts.
+func (s *StreamConsumer) handleRecord(ctx context.Context, shardID string, r streamtypes.Record) error {
+	if r.Dynamodb == nil {
+		return nil
+	}
+	record := &streamRecord{
+		eventID:        aws.ToString(r.EventID),
+		eventName:      string(r.EventName),
+		shardID:        shardID,
+		sequenceNumber: aws.ToString(r.Dynamodb.SequenceNumber),
+		created:        aws.ToTime(r.Dynamodb.ApproximateCreationDateTime),
+		keys:           fromStreamItem(r.Dynamodb.Keys),
+		newImage:       fromStreamItem(r.Dynamodb.NewImage),
+		oldImage:       fromStreamItem(r.Dynamodb.OldImage),
+	}
+
+	image := record.newImage
+	if image == nil {
+		image = record.oldImage
+	}
+	entityType, ok := image[s.entityAttribute].(*types.AttributeValueMemberS)
+	if !ok {
+		return nil
+	}
+	handler, ok := s.handlers[getSplittedKey(entityType.Value, s.c.keySeparator)]
+	if !ok {
+		return nil
+	}
+
+	for _, image := range []map[string]types.AttributeValue{record.newImage, record.oldImage} {
+		if image == nil {
+			continue
+		}
+		if err := s.c.decodeItem(ctx, image); err != nil {
+			return fmt.Errorf("record %s: %w", record.sequenceNumber, err)
+		}
+	}
+	event, err := handler.decode(record)
+	if err != nil {
+		return fmt.Errorf("record %s: %w", record.sequenceNumber, err)
+	}
+
+	wait := s.retryWait
+	for attempt := 0; ; attempt++ {
+		err := handler.handle(ctx, event)
+		if err == nil {
+			return nil
+		}
+		if attempt >= s.maxRetry {
+			return fmt.Errorf("record %s: handler failed after %d attempts: %w", record.sequenceNumber, attempt+1, err)
+		}
+		if err := sleepContext(ctx, wait); err != nil {
+			return err
+		}
+		wait *= 2
+	}
+}
+
+// fromStreamItem converts an item image of a stream record to DynamoDB attribute values.
+func fromStreamItem(item map[string]streamtypes.AttributeValue) map[string]types.AttributeValue {
+	if item == nil {
+		return nil
+	}
+	out := make(map[string]types.AttributeValue, len(item))
+	for name, v := range item {
+		out[name] = fromStreamAttributeValue(v)
+	}
+	return out
+}
+
+// fromStreamAttributeValue converts a stream attribute value to a DynamoDB attribute value.
+func fromStreamAttributeValue(av streamtypes.AttributeValue) types.AttributeValue {
+	switch v := av.(type) {
+	case *streamtypes.AttributeValueMemberS:
+		return &types.AttributeValueMemberS{Value: v.Value}
+	case *streamtypes.AttributeValueMemberN:
+		return &types.AttributeValueMemberN{Value: v.Value}
+	case *streamtypes.AttributeValueMemberB:
+		return &types.AttributeValueMemberB{Value: v.Value}
+	case *streamtypes.AttributeValueMemberBOOL:
+		return &types.AttributeValueMemberBOOL{Value: v.Value}
+	case *streamtypes.AttributeValueMemberNULL:
+		return &types.AttributeValueMemberNULL{Value: v.Value}
+	case *streamtypes.AttributeValueMemberSS:
+		return &types.AttributeValueMemberSS{Value: v.Value}
+	case *streamtypes.AttributeValueMemberNS:
+		return &types.AttributeValueMemberNS{Value: v.Value}
+	case *streamtypes.AttributeValueMemberBS:
+		return &types.AttributeValueMemberBS{Value: v.Value}
+	case *streamtypes.AttributeValueMemberL:
+		list := make([]types.AttributeValue, len(v.Value))
+		for n, elem := range v.Value {
+			list[n] = fromStreamAttributeValue(elem)
+		}
+		return &types.AttributeValueMemberL{Value: list}
+	case *streamtypes.AttributeValueMemberM:
+		return &types.AttributeValueMemberM{Value: fromStreamItem(v.Value)}
+	}
+	return nil
+}
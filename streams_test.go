@@ -0,0 +1,262 @@
+package dygo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodbstreams"
+	streamtypes "github.com/aws/aws-sdk-go-v2/service/dynamodbstreams/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeStream is a closed stream whose shards return their records in pages of pageSize records, one by default.
+type fakeStream struct {
+	shards   []streamtypes.Shard
+	records  map[string][]streamtypes.Record
+	pageSize int
+}
+
+func (f *fakeStream) DescribeStream(ctx context.Context, params *dynamodbstreams.DescribeStreamInput, optFns ...func(*dynamodbstreams.Options)) (*dynamodbstreams.DescribeStreamOutput, error) {
+	return &dynamodbstreams.DescribeStreamOutput{
+		StreamDescription: &streamtypes.StreamDescription{Shards: f.shards},
+	}, nil
+}
+
+func (f *fakeStream) GetShardIterator(ctx context.Context, params *dynamodbstreams.GetShardIteratorInput, optFns ...func(*dynamodbstreams.Options)) (*dynamodbstreams.GetShardIteratorOutput, error) {
+	position := 0
+	if params.ShardIteratorType == streamtypes.ShardIteratorTypeAfterSequenceNumber {
+		for n, r := range f.records[*params.ShardId] {
+			if *r.Dynamodb.SequenceNumber == *params.SequenceNumber {
+				position = n + 1
+			}
+		}
+	}
+	return &dynamodbstreams.GetShardIteratorOutput{
+		ShardIterator: aws.String(fmt.Sprintf("%s/%d", *params.ShardId, position)),
+	}, nil
+}
+
+func (f *fakeStream) GetRecords(ctx context.Context, params *dynamodbstreams.GetRecordsInput, optFns ...func(*dynamodbstreams.Options)) (*dynamodbstreams.GetRecordsOutput, error) {
+	var shardID string
+	var position int
+	fmt.Sscanf(*params.ShardIterator, "%3s/%d", &shardID, &position)
+	records := f.records[shardID]
+	if position >= len(records) {
+		return &dynamodbstreams.GetRecordsOutput{}, nil
+	}
+	end := position + 1
+	if f.pageSize > 1 {
+		end = position + f.pageSize
+	}
+	if end > len(records) {
+		end = len(records)
+	}
+	return &dynamodbstreams.GetRecordsOutput{
+		Records:           records[position:end],
+		NextShardIterator: aws.String(fmt.Sprintf("%s/%d", shardID, end)),
+	}, nil
+}
+
+func streamRecordOf(seq int, event streamtypes.OperationType, pk, name string) streamtypes.Record {
+	image := map[string]streamtypes.AttributeValue{
+		"_partition_key": &streamtypes.AttributeValueMemberS{Value: pk},
+		"_sort_key":      &streamtypes.AttributeValueMemberS{Value: "current"},
+		"name":           &streamtypes.AttributeValueMemberS{Value: name},
+	}
+	r := streamtypes.Record{
+		EventID:   aws.String(strconv.Itoa(seq)),
+		EventName: event,
+		Dynamodb: &streamtypes.StreamRecord{
+			SequenceNumber: aws.String(fmt.Sprintf("%05d", seq)),
+			Keys:           map[string]streamtypes.AttributeValue{"_partition_key": image["_partition_key"]},
+		},
+	}
+	if event == streamtypes.OperationTypeRemove {
+		r.Dynamodb.OldImage = image
+	} else {
+		r.Dynamodb.NewImage = image
+	}
+	return r
+}
+
+func newFakeStream() *fakeStream {
+	return &fakeStream{
+		// the child shard is listed first, it must still be read after its parent
+		shards: []streamtypes.Shard{
+			{ShardId: aws.String("s-2"), ParentShardId: aws.String("s-1")},
+			{ShardId: aws.String("s-1")},
+		},
+		records: map[string][]streamtypes.Record{
+			"s-1": {
+				streamRecordOf(1, streamtypes.OperationTypeInsert, "rm#1", "first"),
+				streamRecordOf(2, streamtypes.OperationTypeInsert, "fl#1", "floor"),
+			},
+			"s-2": {
+				streamRecordOf(3, streamtypes.OperationTypeModify, "rm#1", "second"),
+				streamRecordOf(4, streamtypes.OperationTypeRemove, "rm#1", "second"),
+			},
+		},
+	}
+}
+
+func Test_stream_consumer_follows_shard_lineage(t *testing.T) {
+	c := &Client{tableName: "test-table-1", partitionKey: "_partition_key", sortKey: "_sort_key", keySeparator: "#"}
+	checkpoints := NewMemoryCheckpointStore()
+	consumer := c.NewStreamConsumer(newFakeStream(), "arn:stream", WithCheckpointStore(checkpoints), WithStreamRetry(2, time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var mu sync.Mutex
+	var events []StreamEvent[cachedItem]
+	failures := 1
+	RegisterStreamHandler(consumer, "rm", func(ctx context.Context, e StreamEvent[cachedItem]) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if e.SequenceNumber == "00003" && failures > 0 {
+			failures--
+			return errors.New("temporary failure")
+		}
+		events = append(events, e)
+		if len(events) == 3 {
+			cancel()
+		}
+		return nil
+	})
+
+	err := consumer.Run(ctx)
+	assert.True(t, errors.Is(err, context.Canceled))
+
+	if !assert.Len(t, events, 3) {
+		return
+	}
+	assert.Equal(t, "first", events[0].NewImage.Name)
+	assert.Equal(t, "second", events[1].NewImage.Name)
+	assert.Equal(t, "MODIFY", events[1].EventName)
+	assert.Nil(t, events[2].NewImage)
+	assert.Equal(t, "second", events[2].OldImage.Name)
+
+	checkpoint, _ := checkpoints.GetCheckpoint(context.Background(), "arn:stream", "s-1")
+	assert.Equal(t, shardEnd, checkpoint)
+}
+
+func Test_stream_consumer_resumes_from_checkpoint(t *testing.T) {
+	c := &Client{tableName: "test-table-1", partitionKey: "_partition_key", sortKey: "_sort_key", keySeparator: "#"}
+	checkpoints := NewMemoryCheckpointStore()
+	checkpoints.SetCheckpoint(context.Background(), "arn:stream", "s-1", shardEnd)
+	checkpoints.SetCheckpoint(context.Background(), "arn:stream", "s-2", "00003")
+	consumer := c.NewStreamConsumer(newFakeStream(), "arn:stream", WithCheckpointStore(checkpoints), WithStreamRetry(3, time.Millisecond))
+
+	var seen []string
+	RegisterStreamHandler(consumer, "rm", func(ctx context.Context, e StreamEvent[cachedItem]) error {
+		seen = append(seen, e.SequenceNumber)
+		return errors.New("permanent failure")
+	})
+
+	err := consumer.Run(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, []string{"00004", "00004", "00004", "00004"}, seen)
+}
+
+func Test_dynamodb_checkpoint_store(t *testing.T) {
+	fake := newFakeDynamoDB()
+	store := NewDynamoDBCheckpointStore(newFakeClient(t, fake), "checkpoint#")
+
+	checkpoint, err := store.GetCheckpoint(context.Background(), "arn:stream", "s-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "", checkpoint)
+
+	assert.NoError(t, store.SetCheckpoint(context.Background(), "arn:stream", "s-1", "00042"))
+	checkpoint, err = store.GetCheckpoint(context.Background(), "arn:stream", "s-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "00042", checkpoint)
+	assert.Equal(t, 1, fake.count("test-table-1"))
+}
+
+// fakeCheckpointWrites returns the sequence numbers of the checkpoints written with PutItem, failing them with err if set.
+func fakeCheckpointWrites(fake *fakeDynamoDB, err map[string]string, written func(string)) {
+	fake.hook = func(op string, req map[string]json.RawMessage) (int, any) {
+		if op != "PutItem" {
+			return 0, nil
+		}
+		if err != nil {
+			return http.StatusBadRequest, err
+		}
+		var item map[string]map[string]string
+		json.Unmarshal(req["Item"], &item)
+		written(item[checkpointAttribute]["S"])
+		return 0, nil
+	}
+}
+
+func Test_stream_consumer_skips_own_checkpoint_records(t *testing.T) {
+	fake := newFakeDynamoDB()
+	c := newFakeClient(t, fake)
+	checkpointRecord := streamtypes.Record{
+		EventID:   aws.String("2"),
+		EventName: streamtypes.OperationTypeModify,
+		Dynamodb: &streamtypes.StreamRecord{
+			SequenceNumber: aws.String("00002"),
+			Keys: map[string]streamtypes.AttributeValue{
+				"_partition_key": &streamtypes.AttributeValueMemberS{Value: "checkpoint#arn:stream"},
+				"_sort_key":      &streamtypes.AttributeValueMemberS{Value: "s-1"},
+			},
+		},
+	}
+	stream := &fakeStream{
+		shards: []streamtypes.Shard{{ShardId: aws.String("s-1")}},
+		records: map[string][]streamtypes.Record{
+			"s-1": {streamRecordOf(1, streamtypes.OperationTypeInsert, "rm#1", "first"), checkpointRecord},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var mu sync.Mutex
+	var written []string
+	fakeCheckpointWrites(fake, nil, func(sequenceNumber string) {
+		mu.Lock()
+		defer mu.Unlock()
+		written = append(written, sequenceNumber)
+		if sequenceNumber == shardEnd {
+			cancel()
+		}
+	})
+	consumer := c.NewStreamConsumer(stream, "arn:stream", WithCheckpointStore(NewDynamoDBCheckpointStore(c, "checkpoint#")))
+	RegisterStreamHandler(consumer, "rm", func(ctx context.Context, e StreamEvent[cachedItem]) error {
+		return nil
+	})
+
+	err := consumer.Run(ctx)
+	assert.True(t, errors.Is(err, context.Canceled), err)
+	// the batch holding only the record of the checkpoint isn't checkpointed
+	assert.Equal(t, []string{"00001", shardEnd}, written)
+}
+
+func Test_stream_consumer_reports_checkpoint_failure(t *testing.T) {
+	fake := newFakeDynamoDB()
+	c := newFakeClient(t, fake)
+	stream := newFakeStream()
+	stream.pageSize = 2
+	fakeCheckpointWrites(fake, fakeError("ValidationException", "checkpoint rejected"), nil)
+	consumer := c.NewStreamConsumer(stream, "arn:stream",
+		WithCheckpointStore(NewDynamoDBCheckpointStore(c, "checkpoint#")), WithStreamRetry(0, time.Millisecond))
+
+	failure := errors.New("permanent failure")
+	RegisterStreamHandler(consumer, "fl", func(ctx context.Context, e StreamEvent[cachedItem]) error {
+		return failure
+	})
+
+	// the checkpoint of the record handled before the failure can't be written either
+	err := consumer.Run(context.Background())
+	assert.True(t, errors.Is(err, failure), err)
+	assert.True(t, errors.Is(err, ErrValidation), err)
+	assert.Contains(t, err.Error(), "failed to write checkpoint")
+}
@@ -0,0 +1,54 @@
+package dygo
+
+import (
+	"context"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodbstreams"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// operationTimeoutID is the ID of the middleware applying the timeouts set with WithOperationTimeout.
+const operationTimeoutID = "DygoOperationTimeout"
+
+// timeoutOperations are the DynamoDB and DynamoDB Streams API operations called by the package,
+// the operations a timeout can be set for with WithOperationTimeout.
+var timeoutOperations = map[string]bool{
+	"BatchExecuteStatement": true,
+	"BatchGetItem":          true,
+	"BatchWriteItem":        true,
+	"DeleteItem":            true,
+	"ExecuteStatement":      true,
+	"ExecuteTransaction":    true,
+	"GetItem":               true,
+	"PutItem":               true,
+	"Query":                 true,
+	"Scan":                  true,
+	"UpdateItem":            true,
+	"DescribeStream":        true,
+	"GetShardIterator":      true,
+	"GetRecords":            true,
+}
+
+// addOperationTimeout adds to the middleware stack of a DynamoDB client the timeouts set with WithOperationTimeout.
+// The middleware runs once per operation, so the timeout covers all the attempts of a request.
+func (c *Client) addOperationTimeout(stack *middleware.Stack) error {
+	return stack.Initialize.Add(middleware.InitializeMiddlewareFunc(operationTimeoutID, func(
+		ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler,
+	) (middleware.InitializeOutput, middleware.Metadata, error) {
+		// the operation name is registered by an earlier middleware of the step
+		if d, ok := c.operationTimeouts[awsmiddleware.GetOperationName(ctx)]; ok {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, d)
+			defer cancel()
+		}
+		return next.HandleInitialize(ctx, in)
+	}), middleware.After)
+}
+
+// streamsOptions applies the timeouts set with WithOperationTimeout to a request of a DynamoDB Streams client.
+func (c *Client) streamsOptions(o *dynamodbstreams.Options) {
+	if len(c.operationTimeouts) > 0 {
+		o.APIOptions = append(o.APIOptions, c.addOperationTimeout)
+	}
+}
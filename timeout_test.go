@@ -0,0 +1,92 @@
+package dygo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodbstreams"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_operation_timeout(t *testing.T) {
+	fake := newFakeDynamoDB()
+	c := &Client{}
+	assert.Error(t, WithOperationTimeout("", time.Second)(c))
+	assert.Error(t, WithOperationTimeout("Getitem", time.Second)(c))
+	assert.Error(t, WithOperationTimeout("GetItem", 0)(c))
+	assert.NoError(t, WithOperationTimeout("GetItem", 50*time.Millisecond)(c))
+
+	db := newFakeClient(t, fake, func(o *dynamodb.Options) {
+		o.APIOptions = append(o.APIOptions, c.addOperationTimeout)
+	})
+	db.operationTimeouts = c.operationTimeouts
+	fake.hook = func(op string, req map[string]json.RawMessage) (int, any) {
+		time.Sleep(200 * time.Millisecond)
+		return 0, nil
+	}
+
+	start := time.Now()
+	var d dataItem
+	err := db.PK(newPK("room")).SK(Equal("current")).GetItem(context.Background(), &d)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), err)
+	assert.Less(t, int64(time.Since(start)), int64(200*time.Millisecond))
+
+	// other operations aren't limited
+	err = db.PK(newPK("room")).SK(Equal("current")).Delete(context.Background())
+	assert.True(t, errors.Is(err, ErrNotFound), err)
+}
+
+func Test_operation_timeout_of_streams(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+	api := dynamodbstreams.New(dynamodbstreams.Options{
+		Region:           "ap-northeast-1",
+		Credentials:      aws.AnonymousCredentials{},
+		EndpointResolver: dynamodbstreams.EndpointResolverFromURL(srv.URL),
+		Retryer:          aws.NopRetryer{},
+	})
+	c := &Client{tableName: "test-table-1", partitionKey: "_partition_key"}
+	if err := WithOperationTimeout("DescribeStream", 50*time.Millisecond)(c); err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+
+	start := time.Now()
+	_, err := c.NewStreamConsumer(api, "arn:stream").describeShards(context.Background())
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), err)
+	assert.Less(t, int64(time.Since(start)), int64(200*time.Millisecond))
+}
+
+func Test_batch_writers_honour_context(t *testing.T) {
+	fake := newFakeDynamoDB()
+	db := newFakeClient(t, fake)
+	db.writeRate = newRateController(1)
+
+	item := new(Item)
+	for n := 0; n < 5; n++ {
+		db.Item(dataItem{PK: newPK("room"), SK: "current", EntityType: "room"}).AddBatchUpsertItem(item)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := item.BatchUpsertItem(ctx, 1)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), err)
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+
+	item = new(Item)
+	db.PK(newPK("room")).SK(Equal("current")).AddBatchDeleteItem(item)
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	err = item.BatchDeleteItem(ctx, 1)
+	assert.True(t, errors.Is(err, context.Canceled), err)
+}
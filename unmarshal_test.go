@@ -160,7 +160,7 @@ func TestOutput_Unmarshal(t *testing.T) {
 				},
 				indexName:                 "_object_type_index",
 				customEntityTypeAttribute: "",
-				projection:                "",
+				projection:                nil,
 				useGSI:                    false,
 				item:                      nil,
 				err:                       nil,
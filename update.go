@@ -2,65 +2,160 @@ package dygo
 
 import (
 	"context"
+	"errors"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
-	"golang.org/x/sync/errgroup"
 )
 
 const opUpdate = "Update"
 
+// maxUpdateRetries is the number of times a throttled update is retried.
+const maxUpdateRetries = 5
+
+// Update updates the items added with AddUpdateRawItem and AddUpdateItem, using a pool of n workers.
+// It returns the first error encountered, after which the remaining updates are canceled.
+//
+// Example:
+//
+//	newItem := new(Item)
+//	for _, item := range items {
+//		db.UpdateItemRaw(item).AddUpdateRawItem(newItem)
+//	}
+//
+//	err = newItem.Update(context.Background(), 5)
 func (i *Item) Update(ctx context.Context, n int) error {
+	_, err := i.UpdateResult(ctx, n)
+	return err
+}
+
+// UpdateResult updates the items like Update, and also returns the outcome of the update of each item.
+// The n workers, at least one, take the items in turn, at the rate set with WithWriteRate if any.
+// Throttled updates are retried with an exponential backoff and jitter, and the items still throttled
+// after maxUpdateRetries retries are reported as unprocessed. The updates whose condition failed
+// are reported as failed with an error matching ErrConditionFailed.
+// With ContinueOnError, the failure of an update doesn't stop the others; otherwise the items
+// not attempted after the first failure are reported as skipped.
+//
+// Example:
+//
+//	result, err := item.ContinueOnError().UpdateResult(context.Background(), 10)
+//	for _, failure := range result.Failed {
+//		log.Printf("update of %v failed: %v", failure.Key, failure.Err)
+//	}
+func (i *Item) UpdateResult(ctx context.Context, n int) (*BatchResult, error) {
+	result := newBatchResult(opUpdate)
 	if i.err != nil {
-		return i.err
+		return result, i.err
 	}
 
-	// Calculate batch size per goroutine
-	batchSize := (len(i.batchData.updateItems) + n - 1) / n
-
-	g, ctx := errgroup.WithContext(ctx)
-
-	for j := 0; j < n; j++ {
-		// Calculate start and end indices for the current batch
-		start := j * batchSize
-		end := start + batchSize
-		if end > len(i.batchData.updateItems) {
-			end = len(i.batchData.updateItems)
-		}
+	count := len(i.batchData.updateItems)
+	if n > count {
+		n = count
+	}
+	if n < 1 {
+		n = 1
+	}
 
+	g, ctx := i.batchGroup(ctx)
+	indexes := make(chan int)
+	for w := 0; w < n; w++ {
 		g.Go(func() error {
-			for index := range i.batchData.updateItems[start:end] {
-				// Construct an expression for each item
-				expr, err := i.getUpdateItemExpression(start + index)
-				if err != nil {
-					return dynamoError().method(opUpdate).message(err.Error())
+			for index := range indexes {
+				if err := ctx.Err(); err != nil {
+					// the operation stopped after the item was handed out
+					result.skip(BatchKey{Table: i.c.tableName, Key: i.getUpdateItemKey(index)})
+					result.addError(dynamoError().method(opUpdate).wrap(err))
+					continue
 				}
-
-				key := i.getUpdateItemKey(start + index)
-
-				updateItemInput := &dynamodb.UpdateItemInput{
-					TableName:                 aws.String(i.c.tableName),
-					Key:                       key,
-					UpdateExpression:          expr.Update(),
-					ExpressionAttributeNames:  expr.Names(),
-					ExpressionAttributeValues: expr.Values(),
-					ReturnValues:              types.ReturnValueUpdatedNew,
-				}
-
-				_, err = i.c.client.UpdateItem(ctx, updateItemInput)
-				if err != nil {
-					return dynamoError().method(opUpdate).message(err.Error())
+				if err := i.processUpdate(ctx, index, result); err != nil && !i.continueOnError {
+					return err
 				}
 			}
 			return nil
 		})
 	}
 
-	// Wait for all goroutines to complete and return the first encountered error
-	if err := g.Wait(); err != nil {
+	next := 0
+feed:
+	for ; next < count; next++ {
+		select {
+		case indexes <- next:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(indexes)
+
+	// Wait for all workers to complete, their errors are recorded in the result
+	_ = g.Wait()
+	for ; next < count; next++ {
+		result.skip(BatchKey{Table: i.c.tableName, Key: i.getUpdateItemKey(next)})
+		result.addError(dynamoError().method(opUpdate).wrap(ctx.Err()))
+	}
+	return result, result.Err()
+}
+
+// processUpdate updates the item at index, retrying while it is throttled, and records the outcome in the result.
+// It returns the error of a failed update.
+func (i *Item) processUpdate(ctx context.Context, index int, result *BatchResult) error {
+	key := i.getUpdateItemKey(index)
+	batchKey := BatchKey{Table: i.c.tableName, Key: key}
+
+	// Construct an expression for the item
+	expr, err := i.getUpdateItemExpression(index)
+	if err != nil {
+		err = validationError().method(opUpdate).wrap(err)
+		result.fail(err, batchKey)
 		return err
 	}
 
-	return nil
+	updateItemInput := &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(i.c.tableName),
+		Key:                       key,
+		UpdateExpression:          expr.Update(),
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		ReturnConsumedCapacity:    types.ReturnConsumedCapacityTotal,
+	}
+
+	for attempt := 0; ; attempt++ {
+		reservation, err := i.c.writeRate.wait(ctx, 1)
+		if err != nil {
+			err = dynamoError().method(opUpdate).wrap(err)
+			result.fail(err, batchKey)
+			return err
+		}
+
+		output, err := i.c.client.UpdateItem(ctx, updateItemInput)
+		i.c.invalidateCache(i.c.tableName, key)
+		if err == nil {
+			i.c.writeRate.succeeded()
+			if output.ConsumedCapacity != nil {
+				result.succeed(batchKey, *output.ConsumedCapacity)
+			} else {
+				result.succeed(batchKey)
+			}
+			return nil
+		}
+
+		err = i.c.opError(opUpdate, key, err)
+		if !errors.Is(err, ErrThrottled) {
+			result.fail(err, batchKey)
+			return err
+		}
+
+		i.c.writeRate.throttled(reservation)
+		if attempt >= maxUpdateRetries {
+			result.unprocessed(batchKey)
+			return nil
+		}
+		if err := sleepContext(ctx, backoff(attempt)); err != nil {
+			err = dynamoError().method(opUpdate).wrap(err)
+			result.fail(err, batchKey)
+			return err
+		}
+	}
 }
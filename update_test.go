@@ -2,7 +2,16 @@ package dygo
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
 	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
 )
 
 func Test_update_item_happy_path(t *testing.T) {
@@ -66,3 +75,105 @@ func Test_update_item_not_exist(t *testing.T) {
 		removeItem(t, id, "current")
 	}
 }
+
+func Test_update_conditions_and_expressions(t *testing.T) {
+	fake := newFakeDynamoDB()
+	db := newFakeClient(t, fake)
+	existing, missing := newPK("room"), newPK("room")
+	key := func(pk string) map[string]types.AttributeValue {
+		return map[string]types.AttributeValue{
+			"_partition_key": &types.AttributeValueMemberS{Value: pk},
+			"_sort_key":      &types.AttributeValueMemberS{Value: "current"},
+		}
+	}
+	old := key(existing)
+	old["version"] = &types.AttributeValueMemberN{Value: "1"}
+	fake.put("test-table-1", old)
+
+	item := new(Item)
+	for _, pk := range []string{existing, missing} {
+		db.PK(pk).SK(Equal("current")).
+			Condition("version", ConditionAttributeExists()).
+			AddUpdateItem(item, expression.Set(expression.Name("version"), expression.Value(2)))
+	}
+	raw := key(existing)
+	raw["logical_name"] = &types.AttributeValueMemberS{Value: "hall"}
+	db.UpdateItemRaw(raw).Condition("version", ConditionAttributeNotExists()).AddUpdateRawItem(item)
+
+	result, err := item.ContinueOnError().UpdateResult(context.Background(), 0)
+	assert.True(t, errors.Is(err, ErrConditionFailed), err)
+	assert.Equal(t, []BatchKey{{Table: "test-table-1", Key: key(existing)}}, result.Succeeded)
+	if assert.Len(t, result.Failed, 2) {
+		for _, failure := range result.Failed {
+			assert.True(t, errors.Is(failure.Err, ErrConditionFailed), failure.Err)
+		}
+	}
+	updated := fake.get("test-table-1", key(existing))
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "2"}, updated["version"])
+	assert.Nil(t, updated["logical_name"])
+	assert.Nil(t, fake.get("test-table-1", key(missing)))
+
+	item = new(Item)
+	db.PK(existing).SK(Equal("current")).AddUpdateItem(item, expression.UpdateBuilder{})
+	_, err = item.UpdateResult(context.Background(), 1)
+	assert.True(t, errors.Is(err, ErrValidation), err)
+}
+
+func Test_update_retries_throttled(t *testing.T) {
+	fastBackoff(t)
+	fake := newFakeDynamoDB()
+	db := newFakeClient(t, fake)
+	flaky, throttled := newPK("room"), newPK("room")
+
+	var mu sync.Mutex
+	failures := 2
+	fake.hook = func(op string, req map[string]json.RawMessage) (int, any) {
+		mu.Lock()
+		defer mu.Unlock()
+		if strings.Contains(string(req["Key"]), flaky) {
+			if failures == 0 {
+				return 0, nil
+			}
+			failures--
+		}
+		if strings.Contains(string(req["Key"]), flaky) || strings.Contains(string(req["Key"]), throttled) {
+			return http.StatusBadRequest, fakeError("ProvisionedThroughputExceededException", "slow down")
+		}
+		return 0, nil
+	}
+
+	item := new(Item)
+	for _, pk := range []string{flaky, throttled} {
+		db.PK(pk).SK(Equal("current")).
+			AddUpdateItem(item, expression.Set(expression.Name("logical_name"), expression.Value("hall")))
+	}
+	result, err := item.UpdateResult(context.Background(), 2)
+	assert.True(t, errors.Is(err, ErrThrottled), err)
+	assert.Len(t, result.Succeeded, 1)
+	assert.Empty(t, result.Failed)
+	if assert.Len(t, result.Unprocessed, 1) {
+		assert.Equal(t, &types.AttributeValueMemberS{Value: throttled}, result.Unprocessed[0].Key["_partition_key"])
+	}
+	// the flaky item succeeds on the third attempt, the throttled one is retried maxUpdateRetries times
+	assert.Equal(t, 3+maxUpdateRetries+1, fake.callCount("UpdateItem"))
+}
+
+func Test_update_skips_after_failure(t *testing.T) {
+	fake := newFakeDynamoDB()
+	db := newFakeClient(t, fake)
+	fake.hook = func(op string, req map[string]json.RawMessage) (int, any) {
+		return http.StatusBadRequest, fakeError("ValidationException", "invalid update")
+	}
+
+	item := new(Item)
+	for n := 0; n < 10; n++ {
+		db.PK(newPK("room")).SK(Equal("current")).
+			AddUpdateItem(item, expression.Set(expression.Name("logical_name"), expression.Value("hall")))
+	}
+	result, err := item.UpdateResult(context.Background(), 1)
+	assert.True(t, errors.Is(err, ErrValidation), err)
+	assert.Empty(t, result.Succeeded)
+	assert.Len(t, result.Failed, 1)
+	assert.Len(t, result.Skipped, 9)
+	assert.Equal(t, 1, fake.callCount("UpdateItem"))
+}
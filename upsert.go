@@ -4,8 +4,8 @@ import (
 	"context"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 )
 
 const opUpsert = "Upsert"
@@ -21,12 +21,16 @@ func (i *Item) Upsert(ctx context.Context) error {
 
 	err := i.item.Validate()
 	if err != nil {
-		return dynamoError().method(opUpsert).message(err.Error())
+		return validationError().method(opUpsert).wrap(err)
 	}
 
-	av, err := attributevalue.MarshalMap(i.item)
+	av, err := i.c.marshalItem(ctx, i.item)
 	if err != nil {
-		return dynamoError().method(opUpsert).message(err.Error())
+		return dynamoError().method(opUpsert).wrap(err)
+	}
+
+	if err := i.c.checkItemSize(av); err != nil {
+		return dynamoError().method(opUpsert).wrap(joinCleanupError(err, i.c.deleteBlobs(ctx, blobKeys(av))))
 	}
 
 	input := dynamodb.PutItemInput{
@@ -37,18 +41,26 @@ func (i *Item) Upsert(ctx context.Context) error {
 	if i.condition.IsSet() {
 		expr, err := i.getConditionalUpdateExpression()
 		if err != nil {
-			return dynamoError().method(opUpdate).message(err.Error())
+			return dynamoError().method(opUpdate).wrap(joinCleanupError(err, i.c.deleteBlobs(ctx, blobKeys(av))))
 		}
 		input.ConditionExpression = expr.Condition()
 		input.ExpressionAttributeNames = expr.Names()
 		input.ExpressionAttributeValues = expr.Values()
 	}
 
-	if _, err := i.c.client.PutItem(context.TODO(), &input); err != nil {
-		if err := getDynamoDBError(opUpsert, err); err != nil {
-			return err
-		}
-		return dynamoError().method(opUpsert).message(err.Error())
+	if i.c.blobStore != nil {
+		// the previous version of the item is needed to clean up the blobs it referenced
+		input.ReturnValues = types.ReturnValueAllOld
+	}
+
+	output, err := i.c.client.PutItem(ctx, &input)
+	if err != nil {
+		return joinCleanupError(i.opError(opUpsert, err), i.c.deleteBlobs(ctx, blobKeys(av)))
+	}
+	i.c.invalidateCache(i.c.tableName, i.c.primaryKey(av))
+
+	if err := i.c.deleteBlobs(ctx, blobKeys(output.Attributes), av); err != nil {
+		return dynamoError().method(opUpsert).wrap(err)
 	}
 	return nil
 }
@@ -0,0 +1,229 @@
+package dygo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const opWriteBatch = "WriteBatch"
+
+// WriteBatch collects put and delete requests for the tables of any number of clients and writes them together.
+// The requests are packed in chunks of up to 25 requests and 16MB, mixing puts, deletes and tables,
+// and each chunk is sent with one BatchWriteItem call. Requests of clients with different DynamoDB clients
+// are sent in different chunks.
+// A request replaces an earlier request with the same table and key, since DynamoDB rejects duplicate keys in one call.
+//
+// Example:
+//
+//	result, err := dygo.NewWriteBatch().
+//		Put(rooms.Item(room)).
+//		Put(rooms.ItemRaw(raw)).
+//		Delete(bookings.PK(PK).SK(Equal(SK))).
+//		Write(context.Background(), 10)
+type WriteBatch struct {
+	requests        []batchRequest
+	keys            map[string]int
+	continueOnError bool
+	err             error
+}
+
+// batchRequest is a write request of a WriteBatch, with the client of its table and its size.
+// The item of a put added with Item is transformed according to the fields when the batch is written.
+type batchRequest struct {
+	c         *Client
+	table     string
+	request   types.WriteRequest
+	size      int
+	fields    []fieldOptions
+	transform bool
+}
+
+// writeChunk is a set of requests of a WriteBatch sent with one BatchWriteItem call.
+type writeChunk struct {
+	tables   batchTables
+	requests map[string][]types.WriteRequest
+	count    int
+	size     int
+}
+
+// NewWriteBatch returns an empty WriteBatch.
+func NewWriteBatch() *WriteBatch {
+	return &WriteBatch{keys: make(map[string]int)}
+}
+
+// Put adds a put request for the item, created with Item or ItemRaw of the client of its table.
+// An item created with Item is marshalled when added and transformed according to its dygo struct tags
+// when the batch is written, with the context of Write.
+func (b *WriteBatch) Put(item *Item) *WriteBatch {
+	if b.err != nil {
+		return b
+	}
+	if item.err != nil {
+		b.err = item.err
+		return b
+	}
+
+	switch {
+	case item.item != nil:
+		if err := item.item.Validate(); err != nil {
+			b.err = validationError().method(opWriteBatch).wrap(err)
+			return b
+		}
+		av, err := attributevalue.MarshalMap(item.item)
+		if err != nil {
+			b.err = dynamoError().method(opWriteBatch).wrap(err)
+			return b
+		}
+		b.add(batchRequest{
+			c:         item.c,
+			request:   types.WriteRequest{PutRequest: &types.PutRequest{Item: av}},
+			fields:    taggedFields(item.item),
+			transform: true,
+		}, item.c.primaryKey(av))
+	case item.batchData.batchPutRaw != nil:
+		av := item.batchData.batchPutRaw
+		if err := item.c.checkItemSize(av); err != nil {
+			b.err = dynamoError().method(opWriteBatch).wrap(err)
+			return b
+		}
+		b.add(batchRequest{
+			c:       item.c,
+			request: types.WriteRequest{PutRequest: &types.PutRequest{Item: av}},
+			size:    ItemSize(av),
+		}, item.c.primaryKey(av))
+	default:
+		b.err = validationError().method(opWriteBatch).message("item to put is missing")
+	}
+	return b
+}
+
+// Delete adds a delete request for the item with the key set on key, created with PK of the client of its table.
+func (b *WriteBatch) Delete(key *Item) *WriteBatch {
+	if b.err != nil {
+		return b
+	}
+	if key.err != nil {
+		b.err = key.err
+		return b
+	}
+	if len(key.key) == 0 {
+		b.err = validationError().method(opWriteBatch).message(errMissingPartitionKey)
+		return b
+	}
+
+	b.add(batchRequest{
+		c:       key.c,
+		request: types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: key.key}},
+		size:    ItemSize(key.key),
+	}, key.key)
+	return b
+}
+
+// ContinueOnError makes Write go on after the failure of a chunk instead of stopping at the first failure.
+func (b *WriteBatch) ContinueOnError() *WriteBatch {
+	b.continueOnError = true
+	return b
+}
+
+// Write sends the requests of the batch, running threadCount BatchWriteItem calls in parallel,
+// and returns the outcome of each request. The error is the one of BatchResult.Err.
+func (b *WriteBatch) Write(ctx context.Context, threadCount int) (*BatchResult, error) {
+	result := newBatchResult(opWriteBatch)
+	if b.err != nil {
+		return result, b.err
+	}
+
+	requests, err := b.transformRequests(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	g, ctx := newBatchGroup(ctx, b.continueOnError)
+	g.SetLimit(threadCount)
+
+	for _, chunk := range chunkRequests(requests) {
+		chunk := chunk
+		g.Go(func() error {
+			return processBatchWrite(ctx, opWriteBatch, chunk.tables, chunk.requests, result)
+		})
+	}
+
+	// Wait for all chunks to be written, their errors are recorded in the result
+	_ = g.Wait()
+	return result, result.Err()
+}
+
+// add adds the write request for the key in the table of its client, replacing an earlier request with the same key.
+func (b *WriteBatch) add(r batchRequest, key map[string]types.AttributeValue) {
+	r.table = r.c.tableName
+	id := cacheKey(r.table, key)
+	if n, ok := b.keys[id]; ok {
+		b.requests[n] = r
+		return
+	}
+	b.keys[id] = len(b.requests)
+	b.requests = append(b.requests, r)
+}
+
+// transformRequests returns the requests of the batch with the items of the puts added with Item transformed.
+// If an item can't be transformed or is too large, the blobs already offloaded for the items are deleted.
+func (b *WriteBatch) transformRequests(ctx context.Context) ([]batchRequest, error) {
+	requests := make([]batchRequest, len(b.requests))
+	offloaded := make(map[*Client][]string)
+	for n, r := range b.requests {
+		if r.transform {
+			// the marshalled item is kept intact, so the batch can be written again
+			av := copyItem(r.request.PutRequest.Item)
+			err := r.c.transformItem(ctx, av, r.fields)
+			offloaded[r.c] = append(offloaded[r.c], blobKeys(av)...)
+			if err == nil {
+				err = r.c.checkItemSize(av)
+			}
+			if err != nil {
+				return nil, dynamoError().method(opWriteBatch).wrap(joinCleanupError(err, deleteOffloadedBlobs(ctx, offloaded)))
+			}
+			r.request = types.WriteRequest{PutRequest: &types.PutRequest{Item: av}}
+			r.size = ItemSize(av)
+		}
+		requests[n] = r
+	}
+	return requests, nil
+}
+
+// deleteOffloadedBlobs deletes the blobs offloaded for the items of the clients, from the blob store of their client.
+func deleteOffloadedBlobs(ctx context.Context, offloaded map[*Client][]string) error {
+	var errs []error
+	for c, keys := range offloaded {
+		if err := c.deleteBlobs(ctx, keys); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// chunkRequests packs the requests in chunks of up to writeBatchSize requests and maxBatchWriteSize bytes,
+// one DynamoDB client per chunk.
+func chunkRequests(requests []batchRequest) []*writeChunk {
+	var chunks []*writeChunk
+	open := make(map[*dynamodb.Client]*writeChunk)
+	for _, r := range requests {
+		chunk := open[r.c.client]
+		if chunk == nil || chunk.count == writeBatchSize || chunk.size+r.size > maxBatchWriteSize {
+			chunk = &writeChunk{
+				tables:   batchTables{def: r.c, clients: make(map[string]*Client)},
+				requests: make(map[string][]types.WriteRequest),
+			}
+			open[r.c.client] = chunk
+			chunks = append(chunks, chunk)
+		}
+		chunk.tables.clients[r.table] = r.c
+		chunk.requests[r.table] = append(chunk.requests[r.table], r.request)
+		chunk.count++
+		chunk.size += r.size
+	}
+	return chunks
+}
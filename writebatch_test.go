@@ -0,0 +1,74 @@
+package dygo
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_write_batch(t *testing.T) {
+	fake := newFakeDynamoDB()
+	rooms := newFakeClient(t, fake)
+	bookings := &Client{
+		client:       rooms.client,
+		tableName:    "test-table-2",
+		partitionKey: "_partition_key",
+		sortKey:      "_sort_key",
+		keySeparator: "#",
+	}
+	var calls []map[string]json.RawMessage
+	fake.hook = func(op string, req map[string]json.RawMessage) (int, any) {
+		if op == "BatchWriteItem" {
+			var requests map[string]json.RawMessage
+			json.Unmarshal(req["RequestItems"], &requests)
+			calls = append(calls, requests)
+		}
+		return 0, nil
+	}
+
+	booking := func(pk string) map[string]types.AttributeValue {
+		return map[string]types.AttributeValue{
+			"_partition_key": &types.AttributeValueMemberS{Value: pk},
+			"_sort_key":      &types.AttributeValueMemberS{Value: "current"},
+		}
+	}
+	fake.put("test-table-2", booking("bk-1"))
+	fake.put("test-table-2", booking("bk-2"))
+
+	batch := NewWriteBatch()
+	pks := make([]string, 30)
+	for n := range pks {
+		pks[n] = newPK("room")
+		batch.Put(rooms.Item(dataItem{PK: pks[n], SK: "current", EntityType: "room"}))
+	}
+	batch.
+		Put(bookings.ItemRaw(booking("bk-3"))).
+		Delete(bookings.PK("bk-1").SK(Equal("current"))).
+		Delete(bookings.PK("bk-2").SK(Equal("current"))).
+		// replaces the put of the first room
+		Delete(rooms.PK(pks[0]).SK(Equal("current")))
+
+	result, err := batch.Write(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Len(t, result.Succeeded, 33)
+	if assert.Len(t, calls, 2) {
+		// the second chunk mixes tables, puts and deletes
+		last := calls[0]
+		if len(last) == 1 {
+			last = calls[1]
+		}
+		assert.Len(t, last, 2)
+	}
+	assert.Nil(t, fake.get("test-table-1", booking(pks[0])))
+	assert.NotNil(t, fake.get("test-table-1", booking(pks[1])))
+	assert.Nil(t, fake.get("test-table-2", booking("bk-1")))
+	assert.NotNil(t, fake.get("test-table-2", booking("bk-3")))
+
+	_, err = NewWriteBatch().Put(rooms.Item(dataItem{PK: newPK("room"), SK: "current", EntityType: "table1"})).Write(context.Background(), 1)
+	assert.Error(t, err)
+	_, err = NewWriteBatch().Delete(rooms.PK("")).Write(context.Background(), 1)
+	assert.Error(t, err)
+}